@@ -40,9 +40,12 @@ func getImagesCommand() *cobra.Command {
   helm images get prometheus-standalone --from-release --registry quay.io -o yaml
   helm images get oci://registry-1.docker.io/bitnamicharts/airflow -o yaml
   helm images get kong-2.35.0.tgz -o json
+  helm images get sample git+https://github.com/org/repo//charts/sample?ref=v1.2.0
   helm template example/chart/sample | helm images get --raw -
   helm template example/chart/sample | helm images get --raw - -o yaml
-  helm images get --charts-dir ./charts -o yaml`,
+  helm images get --charts-dir ./charts -o yaml
+  helm images get --charts-dir ./charts --charts-dir-depth 2 --charts-dir-exclude 'deprecated/' -o yaml
+  helm images get sample path/to/chart/sample --variant dev=values-dev.yaml --variant prod=values-prod.yaml -o yaml`,
 		Args:    validateAndSetArgs,
 		PreRunE: setCLIClient,
 		RunE: func(cmd *cobra.Command, _ []string) error {
@@ -52,6 +55,10 @@ func getImagesCommand() *cobra.Command {
 				return images.GetImagesFromChartsDir(cmd.Context())
 			}
 
+			if len(images.Variants) != 0 {
+				return images.GetImagesFromVariants(cmd.Context())
+			}
+
 			if images.Raw {
 				stdIn := cmd.InOrStdin()
 
@@ -73,6 +80,11 @@ func getImagesCommand() *cobra.Command {
 	imageCommand.MarkFlagsMutuallyExclusive("raw", "from-release")
 	imageCommand.MarkFlagsMutuallyExclusive("raw", "charts-dir")
 	imageCommand.MarkFlagsMutuallyExclusive("from-release", "charts-dir")
+	imageCommand.MarkFlagsMutuallyExclusive("exhaustive", "raw")
+	imageCommand.MarkFlagsMutuallyExclusive("exhaustive", "from-release")
+	imageCommand.MarkFlagsMutuallyExclusive("variant", "raw")
+	imageCommand.MarkFlagsMutuallyExclusive("variant", "from-release")
+	imageCommand.MarkFlagsMutuallyExclusive("variant", "charts-dir")
 
 	return imageCommand
 }
@@ -91,7 +103,12 @@ func getAllImagesCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			cmd.SilenceUsage = true
 
-			images.SetNamespace(os.Getenv("HELM_NAMESPACE"))
+			namespace := images.Namespace
+			if len(namespace) == 0 {
+				namespace = os.Getenv("HELM_NAMESPACE")
+			}
+
+			images.SetNamespace(namespace)
 
 			if err := images.SetReleasesToSkips(); err != nil {
 				return err
@@ -148,6 +165,14 @@ func setCLIClient(cmd *cobra.Command, _ []string) error {
 
 	images.SetLogger(images.LogLevel)
 
+	if err := images.SetInjectors(); err != nil {
+		return err
+	}
+
+	if err := images.SetOperatorDefaults(); err != nil {
+		return err
+	}
+
 	if cmd.Use == "all [flags]" {
 		images.SetAll(true)
 	}
@@ -170,6 +195,10 @@ func validateAndSetArgs(cmd *cobra.Command, args []string) error {
 	minArgError := errors.New("[RELEASE] or [CHART] cannot be empty")
 	oneOfThemError := errors.New("when '--from-release' is enabled, only [RELEASE] can be set and not both [RELEASE] [CHART]")
 	defaultReleaseName := "sample"
+
+	if len(images.DefaultReleaseName) != 0 {
+		defaultReleaseName = images.DefaultReleaseName
+	}
 	cmd.SilenceUsage = true
 
 	if images.Raw {
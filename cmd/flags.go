@@ -15,6 +15,10 @@ func registerFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringArrayVar(&images.FileValues, "set-file", []string{},
 		"set values from respective files specified via the command line "+
 			"(can specify multiple or separate values with commas: key1=path1,key2=path2)")
+	cmd.PersistentFlags().StringArrayVar(&images.JSONValues, "set-json", []string{},
+		"set JSON values on the command line (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2)")
+	cmd.PersistentFlags().StringArrayVar(&images.LiteralValues, "set-literal", []string{},
+		"set a literal STRING value on the command line")
 	cmd.PersistentFlags().StringArrayVarP(&images.ShowOnly, "show-only", "s", nil,
 		"only show manifests rendered from the given templates")
 	cmd.PersistentFlags().VarP(&images.ValueFiles, "values", "f",
@@ -22,14 +26,78 @@ func registerFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVarP(&images.Version, "version", "", "",
 		"specify a version constraint for the chart version to use, the value passed here would be used to set "+
 			"--version for helm template command while generating templates")
+	cmd.PersistentFlags().BoolVarP(&images.Devel, "devel", "", false,
+		"use development versions too, equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
+	cmd.PersistentFlags().StringVarP(&images.Namespace, "namespace", "n", "",
+		"namespace the charts are rendered for (.Release.Namespace), and the releases are looked up from, defaults to the one of helm (HELM_NAMESPACE)")
+	cmd.PersistentFlags().StringVarP(&images.DefaultReleaseName, "default-release-name", "", "",
+		"release name the charts are rendered for (.Release.Name) when [RELEASE] is not set, [RELEASE] takes precedence over it. "+
+			"With --charts-dir it is used for every chart in place of the name of the chart")
 	cmd.PersistentFlags().BoolVarP(&images.SkipTests, "skip-tests", "", false,
 		"setting this would set '--skip-tests' for helm template command while generating templates")
 	cmd.PersistentFlags().BoolVarP(&images.SkipCRDS, "skip-crds", "", false,
 		"setting this would set '--skip-crds' for helm template command while generating templates")
+	cmd.PersistentFlags().BoolVarP(&images.IncludeCRDs, "include-crds", "", false,
+		"setting this would set '--include-crds' for helm template command while generating templates")
+	cmd.PersistentFlags().BoolVarP(&images.NoHooks, "no-hooks", "", false,
+		"setting this would set '--no-hooks' for helm template command while generating templates")
+	cmd.PersistentFlags().BoolVarP(&images.IsUpgrade, "is-upgrade", "", false,
+		"setting this would set '--is-upgrade' for helm template command while generating templates")
+	cmd.PersistentFlags().BoolVarP(&images.DependencyUpdate, "dependency-update", "", false,
+		"setting this would set '--dependency-update' for helm template command while generating templates")
 	cmd.PersistentFlags().BoolVarP(&images.Validate, "validate", "", false,
 		"setting this would set '--validate' for helm template command while generating templates")
 	cmd.PersistentFlags().IntVarP(&images.Revision, "revision", "", 0,
 		"revision of your release from which the images to be fetched")
+	cmd.PersistentFlags().StringVarP(&images.RenderMode, "render-mode", "", pkg.RenderModeSDK,
+		"mode of rendering the charts, it should be one of sdk|exec, 'sdk' renders in-process with the helm SDK "+
+			"while 'exec' falls back to invoking 'helm template' of the helm binary set under HELM_BIN")
+	cmd.PersistentFlags().StringVarP(&images.KubeVersion, "kube-version", "", "",
+		"kubernetes version used for Capabilities.KubeVersion while rendering the charts, takes precedence over the one from --capabilities-file")
+	cmd.PersistentFlags().StringSliceVarP(&images.APIVersions, "api-versions", "", nil,
+		"kubernetes api versions used for Capabilities.APIVersions while rendering the charts, added to the ones from --capabilities-file")
+	cmd.PersistentFlags().StringVarP(&images.CapabilitiesFile, "capabilities-file", "", "",
+		"path to the file to load the capabilities from, either the output of 'kubectl api-versions' "+
+			"or a YAML setting 'kubeVersion' and 'apiVersions'")
+	cmd.PersistentFlags().StringSliceVarP(&images.LookupObjects, "lookup-objects", "", nil,
+		"files or directories of kubernetes objects (YAML or JSON, lists included) the 'lookup' template function returns while rendering the charts, "+
+			"in place of the cluster. Supported only with --render-mode sdk")
+	cmd.PersistentFlags().StringVarP(&images.PostRenderer, "post-renderer", "", "",
+		"the path to an executable to be used for post rendering the charts, images are identified from its output. "+
+			"If it exists in $PATH, the binary will be used, otherwise it will try to look for the executable at the given path")
+	cmd.PersistentFlags().StringArrayVarP(&images.PostRendererArgs, "post-renderer-args", "", nil,
+		"an argument to the post-renderer (can specify multiple)")
+	cmd.PersistentFlags().StringVarP(&images.Repo, "repo", "", "",
+		"chart repository url where to locate the requested chart")
+	cmd.PersistentFlags().StringVarP(&images.Username, "username", "", "",
+		"chart repository or OCI registry username where to locate the requested chart")
+	cmd.PersistentFlags().StringVarP(&images.Password, "password", "", "",
+		"chart repository or OCI registry password where to locate the requested chart")
+	cmd.PersistentFlags().StringVarP(&images.CaFile, "ca-file", "", "",
+		"verify certificates of HTTPS-enabled servers using this CA bundle")
+	cmd.PersistentFlags().StringVarP(&images.CertFile, "cert-file", "", "",
+		"identify HTTPS client using this SSL certificate file")
+	cmd.PersistentFlags().StringVarP(&images.KeyFile, "key-file", "", "",
+		"identify HTTPS client using this SSL key file")
+	cmd.PersistentFlags().BoolVarP(&images.InsecureSkipTLSVerify, "images-insecure-skip-tls-verify", "", false,
+		"skip tls certificate checks for the chart download, same as --insecure-skip-tls-verify of helm template")
+	cmd.PersistentFlags().BoolVarP(&images.PassCredentials, "pass-credentials", "", false,
+		"pass credentials to all domains")
+	cmd.PersistentFlags().StringVarP(&images.RegistryConfig, "registry-config", "", "",
+		"path to the registry config file holding the credentials of OCI registries, defaults to the one of helm (HELM_REGISTRY_CONFIG) "+
+			"that 'helm registry login' writes to")
+	cmd.PersistentFlags().BoolVarP(&images.PlainHTTP, "plain-http", "", false,
+		"use insecure HTTP connections for pulling the charts from OCI registries")
+	cmd.PersistentFlags().BoolVarP(&images.NoCache, "no-cache", "", false,
+		"render the charts afresh without reading from or writing to the cache of the renders. "+
+			"Renders are cached by the digest and version of the chart, the values and the flags affecting the render, except with --validate or --dependency-update")
+	cmd.PersistentFlags().StringVarP(&images.CacheDir, "cache-dir", "", "",
+		"directory the renders of the charts are cached under, ex: ~/.cache/helm/images. The renders are cached only when it is set")
+	cmd.PersistentFlags().BoolVarP(&images.Verify, "verify", "", false,
+		"verify the charts against their provenance files before identifying the images, the result is listed under provenance with json/yaml. "+
+			"With --charts-dir only the packaged charts are verified, and the ones failing are reported under warnings unless --strict is set")
+	cmd.PersistentFlags().StringVarP(&images.Keyring, "keyring", "", "",
+		"keyring containing the public keys used to verify the charts with --verify (defaults to $GNUPGHOME/pubring.gpg or ~/.gnupg/pubring.gpg)")
 }
 
 // Registers all common flags to commands, get and all.
@@ -54,6 +122,17 @@ func registerCommonFlags(cmd *cobra.Command) {
 		"when enabled does not color encode the output")
 	cmd.PersistentFlags().BoolVarP(&images.Quiet, "quiet", "q", false,
 		"suppress all log output, only show results")
+	cmd.PersistentFlags().StringVarP(&images.InjectorsConfig, "injectors", "", "",
+		"path to the config of sidecar injectors (istio, linkerd, vault agent, dapr etc.), when set the images those would be injected "+
+			"into the matching workloads are added to the output and marked as 'injected'")
+	cmd.PersistentFlags().StringVarP(&images.OperatorDefaults, "operator-defaults", "", "",
+		"path to the config overriding the built-in images operators (prometheus-operator, eck etc.) default to, listed as 'defaulted'")
+	cmd.PersistentFlags().BoolVarP(&images.Strict, "strict", "", false,
+		"when enabled, fails on the first manifest (or chart with --charts-dir) the images could not be extracted from, "+
+			"instead of reporting it under warnings and proceeding with the rest")
+	cmd.PersistentFlags().BoolVarP(&images.Coverage, "coverage", "", false,
+		"when enabled, reports the manifests skipped as their kind is not one of --kind, along with the fields of them that look like images, "+
+			"listed under coverage with json/yaml and as a table to stderr otherwise")
 }
 
 // Registers all flags to command, get.
@@ -64,6 +143,31 @@ func registerGetFlags(cmd *cobra.Command) {
 		"when enabled, expects raw kubernetes manifests rather helm release or chart")
 	cmd.PersistentFlags().StringVarP(&images.ChartsDir, "charts-dir", "", "",
 		"directory path containing multiple helm charts to process")
+	cmd.PersistentFlags().IntVarP(&images.ChartsDirDepth, "charts-dir-depth", "", pkg.DefaultChartsDirDepth,
+		"depth the charts (and packaged charts) are discovered up to under --charts-dir, ex: 2 for charts/<team>/<app>, negative to discover at any depth. "+
+			"Nothing under a chart, ex: its vendored subcharts, is ever discovered, as it is rendered with the chart")
+	cmd.PersistentFlags().StringSliceVarP(&images.ChartsDirInclude, "charts-dir-include", "", nil,
+		"patterns of .helmignore syntax matched against the paths relative to --charts-dir, only the charts matching them "+
+			"(or under the directories matching them) are discovered, ex: 'team-a/' or 'team-*/app'")
+	cmd.PersistentFlags().StringSliceVarP(&images.ChartsDirExclude, "charts-dir-exclude", "", nil,
+		"patterns of .helmignore syntax matched against the paths relative to --charts-dir, the charts and directories matching them are skipped, ex: 'deprecated/' or '*-0.1.0.tgz'")
+	cmd.PersistentFlags().BoolVarP(&images.DetailedOutput, "detailed-output", "", false,
+		"when enabled, json/yaml output is an object listing the images under images_from_release along with the warnings, coverage, "+
+			"provenance and placeholders, implied by --coverage, --verify and --best-effort")
+	cmd.PersistentFlags().BoolVarP(&images.Exhaustive, "exhaustive", "", false,
+		"when enabled, renders the charts once more with their optional components enabled, i.e. the 'enabled' flags set false in values.yaml "+
+			"and the conditions and tags of the dependencies, and lists the images those appear only then with type 'optional'")
+	cmd.PersistentFlags().BoolVarP(&images.BestEffort, "best-effort", "", false,
+		"when enabled, charts failing to render as they require values are rendered again with placeholders for the values, "+
+			"those are listed under placeholders with json/yaml")
+	cmd.PersistentFlags().IntVarP(&images.BestEffortRetries, "best-effort-retries", "", pkg.DefaultBestEffortRetries,
+		"maximum number of times a chart is rendered again with placeholders, with --best-effort")
+	cmd.PersistentFlags().StringArrayVarP(&images.Variants, "variant", "", nil,
+		"named set of values files to render the chart with, on top of the ones set by --values (can specify multiple), "+
+			"ex: dev=values-dev.yaml | prod=values-prod.yaml,values-prod-ha.yaml")
+	cmd.PersistentFlags().StringVarP(&images.VariantOutput, "variant-output", "", pkg.VariantOutputUnion,
+		"the way images from the variants are listed, it should be one of union|variant, 'union' lists each image once along with "+
+			"the variants using it while 'variant' lists the images of each variant separately")
 }
 
 func registerGetAllFlags(cmd *cobra.Command) {
@@ -0,0 +1,172 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const (
+	// PlaceholderValue is the value set for the values required by the charts, while rendering them with --best-effort.
+	PlaceholderValue = "helm-images-placeholder"
+	// DefaultBestEffortRetries is the default number of times the charts are rendered again with placeholders, with --best-effort.
+	DefaultBestEffortRetries = 10
+	valuesPathGlobal         = "global"
+)
+
+var (
+	// execution error at (sample/templates/secret.yaml:7:14): message, raised by both required and fail.
+	executionErrorRegex = regexp.MustCompile(`execution error at \(([^:()]+):(\d+):\d+\): (.*)`)
+	requiredRegex       = regexp.MustCompile("required\\s+(?:\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`|\\S+)\\s+\\(?\\$?\\.Values((?:\\.[\\w-]+)+)")
+	requiredPipeRegex   = regexp.MustCompile(`\$?\.Values((?:\.[\w-]+)+)\s*\|\s*required\b`)
+	valuesPathRegex     = regexp.MustCompile(`\.Values((?:\.[\w-]+)+)`)
+)
+
+// renderChartBestEffort renders the chart, setting placeholders for the values it fails on as required, and rendering it again
+// up to BestEffortRetries times. The paths of the values set with placeholders are returned along with the manifests.
+func (image *Images) renderChartBestEffort(ctx context.Context) ([]byte, []string, error) {
+	originalStringValues := image.StringValues
+
+	defer func() {
+		image.StringValues = originalStringValues
+	}()
+
+	image.StringValues = slices.Clone(originalStringValues)
+
+	var (
+		chartRequested *chart.Chart
+		placeholders   []string
+	)
+
+	for attempt := 0; ; attempt++ {
+		manifests, err := image.renderChart(ctx)
+		if err == nil {
+			return manifests, placeholders, nil
+		}
+
+		if attempt >= image.BestEffortRetries {
+			return nil, nil, fmt.Errorf("rendering chart with placeholders for %d required value(s) errored: %w", len(placeholders), err)
+		}
+
+		if chartRequested == nil {
+			var loadErr error
+			if chartRequested, loadErr = image.loadChart(ctx); loadErr != nil {
+				return nil, nil, err
+			}
+		}
+
+		valuesPath := getRequiredValuesPath(chartRequested, err.Error(), placeholders)
+		if len(valuesPath) == 0 {
+			return nil, nil, err
+		}
+
+		image.log.Debugf("rendering chart '%s' errored as '%s' is required, rendering again with a placeholder for it", image.chart, valuesPath)
+
+		placeholders = append(placeholders, valuesPath)
+		image.StringValues = append(image.StringValues, fmt.Sprintf("%s=%s", valuesPath, PlaceholderValue))
+	}
+}
+
+// addPlaceholders records the paths of the values set with placeholders under placeholders, skipping the ones already recorded.
+func (image *Images) addPlaceholders(placeholders []string) {
+	if len(placeholders) == 0 {
+		return
+	}
+
+	image.log.Warnf("rendered chart '%s' with placeholders for the required values: %s", image.chart, strings.Join(placeholders, ", "))
+
+	for _, placeholder := range placeholders {
+		if !slices.Contains(image.placeholders, placeholder) {
+			image.placeholders = append(image.placeholders, placeholder)
+		}
+	}
+}
+
+// getRequiredValuesPath returns the path of the value the chart failed to render without, from the `required` at the line of the template
+// the error points to, falling back to the value mentioned by the message of the error. Paths already set with placeholders are not returned again.
+func getRequiredValuesPath(chartRequested *chart.Chart, renderError string, placeholders []string) string {
+	match := executionErrorRegex.FindStringSubmatch(renderError)
+	if len(match) == 0 {
+		return ""
+	}
+
+	templateChart, prefix, templateName := getTemplateChart(chartRequested, match[1])
+
+	candidates := make([]string, 0)
+
+	if line, err := strconv.Atoi(match[2]); err == nil && templateChart != nil {
+		for _, template := range templateChart.Templates {
+			if template.Name != templateName {
+				continue
+			}
+
+			if lines := strings.Split(string(template.Data), "\n"); line > 0 && line <= len(lines) {
+				for _, submatch := range requiredRegex.FindAllStringSubmatch(lines[line-1], -1) {
+					candidates = append(candidates, submatch[1])
+				}
+
+				for _, submatch := range requiredPipeRegex.FindAllStringSubmatch(lines[line-1], -1) {
+					candidates = append(candidates, submatch[1])
+				}
+			}
+		}
+	}
+
+	for _, submatch := range valuesPathRegex.FindAllStringSubmatch(match[3], -1) {
+		candidates = append(candidates, submatch[1])
+	}
+
+	for _, candidate := range candidates {
+		keys := strings.Split(strings.TrimPrefix(candidate, "."), ".")
+		if keys[0] != valuesPathGlobal {
+			keys = append(slices.Clone(prefix), keys...)
+		}
+
+		if valuesPath := getTogglePath(keys); !slices.Contains(placeholders, valuesPath) {
+			return valuesPath
+		}
+	}
+
+	return ""
+}
+
+// getTemplateChart returns the chart or subchart the template belongs to, ex: sample/charts/redis/templates/secret.yaml belongs to the subchart redis,
+// along with the path of the values of it, and the name of the template within it.
+func getTemplateChart(chartRequested *chart.Chart, templatePath string) (*chart.Chart, []string, string) {
+	parts := strings.Split(path.Clean(templatePath), "/")
+	if len(parts) < 2 || parts[0] != chartRequested.Name() { //nolint:mnd
+		return nil, nil, ""
+	}
+
+	currentChart := chartRequested
+	prefix := make([]string, 0)
+	parts = parts[1:]
+
+	for len(parts) > 2 && parts[0] == "charts" { //nolint:mnd
+		name := parts[1]
+		for _, dependency := range currentChart.Metadata.Dependencies {
+			if dependency.Alias == parts[1] {
+				name = dependency.Name
+			}
+		}
+
+		index := slices.IndexFunc(currentChart.Dependencies(), func(dependency *chart.Chart) bool {
+			return dependency.Name() == name
+		})
+		if index < 0 {
+			return nil, nil, ""
+		}
+
+		currentChart = currentChart.Dependencies()[index]
+		prefix = append(prefix, parts[1])
+		parts = parts[2:]
+	}
+
+	return currentChart, prefix, strings.Join(parts, "/")
+}
@@ -0,0 +1,380 @@
+package pkg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+const (
+	// cacheVersion is part of the key of every cached render, bumping it invalidates the renders cached by the earlier versions.
+	cacheVersion = "v1"
+	// chartsCacheDirName is the directory under CacheDir the charts pulled with a pinned version are cached under.
+	chartsCacheDirName = "charts"
+	gitDirName         = ".git"
+)
+
+// cacheEntry is a render of the chart cached under CacheDir.
+type cacheEntry struct {
+	Manifests    string   `json:"manifests"`
+	Placeholders []string `json:"placeholders,omitempty"`
+}
+
+// cacheKey holds everything the render of the chart depends on, the cache key is the digest of it.
+type cacheKey struct {
+	Version           string            `json:"version"`
+	ChartDigest       string            `json:"chart_digest"`
+	ChartVersion      string            `json:"chart_version"`
+	Release           string            `json:"release"`
+	Namespace         string            `json:"namespace"`
+	ValueFiles        []string          `json:"value_files"`
+	Values            []string          `json:"values"`
+	StringValues      []string          `json:"string_values"`
+	FileValues        map[string]string `json:"file_values"`
+	JSONValues        []string          `json:"json_values"`
+	LiteralValues     []string          `json:"literal_values"`
+	ShowOnly          []string          `json:"show_only"`
+	Capabilities      Capabilities      `json:"capabilities"`
+	LookupObjects     string            `json:"lookup_objects"`
+	PostRenderer      string            `json:"post_renderer"`
+	PostRendererArgs  []string          `json:"post_renderer_args"`
+	RenderMode        string            `json:"render_mode"`
+	HelmBin           string            `json:"helm_bin"`
+	SkipTests         bool              `json:"skip_tests"`
+	SkipCRDS          bool              `json:"skip_crds"`
+	IncludeCRDs       bool              `json:"include_crds"`
+	NoHooks           bool              `json:"no_hooks"`
+	IsUpgrade         bool              `json:"is_upgrade"`
+	BestEffort        bool              `json:"best_effort"`
+	BestEffortRetries int               `json:"best_effort_retries"`
+}
+
+// chartCacheKey holds everything the chart pulled with a pinned version is located by, the key of the cached chart is the digest of it.
+type chartCacheKey struct {
+	Version      string `json:"version"`
+	Chart        string `json:"chart"`
+	Repo         string `json:"repo"`
+	ChartVersion string `json:"chart_version"`
+}
+
+// useCache returns true when the renders of the charts are to be cached under CacheDir, caching is enabled only when CacheDir is set.
+// Charts validated against the cluster, or rendered with the dependencies updated afresh, are never cached
+// as the cluster or the latest versions of the dependencies might change in between.
+func (image *Images) useCache() bool {
+	return len(image.CacheDir) != 0 && !image.NoCache && !image.Validate && !image.DependencyUpdate
+}
+
+// renderChartFromCache returns the render of the chart cached under CacheDir, rendering and caching it when not cached already.
+// Charts from the chart repositories or OCI registries are pulled once and rendered from the pulled one, see getCachedChart.
+func (image *Images) renderChartFromCache(ctx context.Context) ([]byte, []string, error) {
+	chartPath, err := image.getCachedChart()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	originalChart := image.chart
+	image.chart = chartPath
+
+	defer func() {
+		image.chart = originalChart
+	}()
+
+	key, err := image.getCacheKey(chartPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(key) == 0 {
+		return image.renderChartWithPlaceholders(ctx)
+	}
+
+	cacheFile := filepath.Join(image.CacheDir, key+".json")
+
+	if entry, err := readCacheEntry(cacheFile); err == nil {
+		image.log.Debugf("using the render of chart '%s' cached at '%s'", originalChart, cacheFile)
+
+		return []byte(entry.Manifests), entry.Placeholders, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		image.log.Warnf("reading the render of chart '%s' cached at '%s' errored, rendering it again: %v", originalChart, cacheFile, err)
+	}
+
+	manifests, placeholders, err := image.renderChartWithPlaceholders(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = writeCacheEntry(cacheFile, cacheEntry{Manifests: string(manifests), Placeholders: placeholders}); err != nil {
+		image.log.Warnf("caching the render of chart '%s' at '%s' errored with: %v", originalChart, cacheFile, err)
+	} else {
+		image.log.Debugf("cached the render of chart '%s' at '%s'", originalChart, cacheFile)
+	}
+
+	return manifests, placeholders, nil
+}
+
+// getCachedChart locates the chart, the charts from the chart repositories or OCI registries pinned to a version by --version are pulled
+// only when not cached under CacheDir already, as the pinned versions do not change. The others are pulled on every run, to get their latest versions.
+func (image *Images) getCachedChart() (string, error) {
+	if _, err := os.Stat(image.chart); err == nil || !image.isChartVersionPinned() {
+		return image.locateChart(image.chart)
+	}
+
+	key, err := getDigest(chartCacheKey{Version: cacheVersion, Chart: image.chart, Repo: image.Repo, ChartVersion: image.Version})
+	if err != nil {
+		return "", err
+	}
+
+	cachedChart := filepath.Join(image.CacheDir, chartsCacheDirName, key+packagedChartExt)
+
+	if _, err = os.Stat(cachedChart); err == nil {
+		image.log.Debugf("using chart '%s' of version '%s' cached at '%s'", image.chart, image.Version, cachedChart)
+
+		return cachedChart, nil
+	}
+
+	chartPath, err := image.locateChart(image.chart)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(chartPath)
+	if err == nil {
+		err = writeCacheFile(cachedChart, content)
+	}
+
+	if err != nil {
+		image.log.Warnf("caching chart '%s' of version '%s' at '%s' errored with: %v", image.chart, image.Version, cachedChart, err)
+	} else {
+		image.log.Debugf("cached chart '%s' of version '%s' at '%s'", image.chart, image.Version, cachedChart)
+	}
+
+	return chartPath, nil
+}
+
+// isChartVersionPinned returns true when --version sets an exact version of the chart, rather than a constraint like ^1.2.0.
+func (image *Images) isChartVersionPinned() bool {
+	_, err := semver.StrictNewVersion(strings.TrimPrefix(image.Version, "v"))
+
+	return err == nil
+}
+
+// getCacheKey returns the key the render of the chart located at chartPath is cached by, the digest of the chart, its version,
+// the content of the values and the flags affecting the render. It is empty when the render cannot be cached, ex: values from remote files.
+func (image *Images) getCacheKey(chartPath string) (string, error) {
+	chartDigest, err := getChartDigest(chartPath)
+	if err != nil {
+		return "", err
+	}
+
+	chartRequested, err := loader.Load(chartPath)
+	if err != nil {
+		return "", err
+	}
+
+	capabilities, err := image.getCapabilities()
+	if err != nil {
+		return "", err
+	}
+
+	key := cacheKey{
+		Version:           cacheVersion,
+		ChartDigest:       chartDigest,
+		ChartVersion:      chartRequested.Metadata.Version,
+		Release:           image.release,
+		Values:            image.Values,
+		StringValues:      image.StringValues,
+		FileValues:        make(map[string]string),
+		JSONValues:        image.JSONValues,
+		LiteralValues:     image.LiteralValues,
+		ShowOnly:          image.ShowOnly,
+		Capabilities:      capabilities,
+		PostRendererArgs:  image.PostRendererArgs,
+		RenderMode:        image.RenderMode,
+		SkipTests:         image.SkipTests,
+		SkipCRDS:          image.SkipCRDS,
+		IncludeCRDs:       image.IncludeCRDs,
+		NoHooks:           image.NoHooks,
+		IsUpgrade:         image.IsUpgrade,
+		BestEffort:        image.BestEffort,
+		BestEffortRetries: image.BestEffortRetries,
+	}
+
+	key.Namespace = image.getReleaseNamespace(cli.New())
+
+	if image.RenderMode == RenderModeExec {
+		key.HelmBin = os.Getenv("HELM_BIN")
+	}
+
+	for _, valueFile := range image.ValueFiles {
+		digest, err := getFileDigest(valueFile)
+		if err != nil {
+			image.log.Debugf("not caching the render of chart '%s', as the values file '%s' could not be read: %v", image.chart, valueFile, err)
+
+			return "", nil
+		}
+
+		key.ValueFiles = append(key.ValueFiles, digest)
+	}
+
+	// Files set by --set-file are read as they are rendered, the missing ones fail the render anyway.
+	for _, fileValue := range image.FileValues {
+		for _, value := range strings.Split(fileValue, ",") {
+			if _, file, found := strings.Cut(value, "="); found {
+				key.FileValues[value], _ = getFileDigest(file)
+			}
+		}
+	}
+
+	if len(image.LookupObjects) != 0 {
+		objects, err := readLookupObjects(image.LookupObjects)
+		if err != nil {
+			return "", err
+		}
+
+		if key.LookupObjects, err = getDigest(objects); err != nil {
+			return "", err
+		}
+	}
+
+	if len(image.PostRenderer) != 0 {
+		key.PostRenderer = image.PostRenderer
+
+		// The post-renderer is digested as well, so that changing it does not return the stale renders.
+		if postRenderer, err := exec.LookPath(image.PostRenderer); err == nil {
+			if digest, err := getFileDigest(postRenderer); err == nil {
+				key.PostRenderer = digest
+			}
+		}
+	}
+
+	return getDigest(key)
+}
+
+// getChartDigest returns the digest of the packaged chart, or of the files of the unpacked one along with their paths.
+func getChartDigest(chartPath string) (string, error) {
+	info, err := os.Stat(chartPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.IsDir() {
+		return getFileDigest(chartPath)
+	}
+
+	files := make(map[string]string)
+
+	if err = filepath.WalkDir(chartPath, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == gitDirName {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		fileInfo, err := os.Stat(file)
+		if err != nil || fileInfo.IsDir() {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(chartPath, file)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(relativePath)], err = getFileDigest(file)
+
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	return getDigest(files)
+}
+
+func getFileDigest(file string) (string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(content)
+
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// getDigest returns the digest of the JSON of the value, maps are sorted by their keys hence the digest of them is stable.
+func getDigest(value interface{}) (string, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(content)
+
+	return hex.EncodeToString(digest[:]), nil
+}
+
+func readCacheEntry(cacheFile string) (*cacheEntry, error) {
+	content, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &cacheEntry{}
+	if err = json.Unmarshal(content, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func writeCacheEntry(cacheFile string, entry cacheEntry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return writeCacheFile(cacheFile, content)
+}
+
+// writeCacheFile writes the content to a temporary file first and renames it, so that the concurrent runs never read a partial one.
+func writeCacheFile(cacheFile string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0o755); err != nil { //nolint:mnd
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(cacheFile), filepath.Base(cacheFile)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(content); err != nil {
+		tempFile.Close()
+
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), cacheFile)
+}
@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
+)
+
+// Capabilities holds the kubernetes capabilities the charts are rendered against, i.e. .Capabilities.KubeVersion and .Capabilities.APIVersions.
+type Capabilities struct {
+	KubeVersion string   `json:"kubeVersion,omitempty" yaml:"kubeVersion,omitempty"`
+	APIVersions []string `json:"apiVersions,omitempty" yaml:"apiVersions,omitempty"`
+}
+
+// getCapabilities returns the capabilities from the file set under CapabilitiesFile merged with the ones set by flags.
+// KubeVersion set by flag takes precedence over the one from the file, while the APIVersions are added up.
+func (image *Images) getCapabilities() (Capabilities, error) {
+	capabilities := Capabilities{}
+
+	if len(image.CapabilitiesFile) != 0 {
+		fileCapabilities, err := readCapabilities(image.CapabilitiesFile)
+		if err != nil {
+			return capabilities, err
+		}
+
+		image.log.Debugf("loaded capabilities from '%s', kube version: '%s' api versions: '%s'",
+			image.CapabilitiesFile, fileCapabilities.KubeVersion, strings.Join(fileCapabilities.APIVersions, ", "))
+
+		capabilities = fileCapabilities
+	}
+
+	if len(image.KubeVersion) != 0 {
+		capabilities.KubeVersion = image.KubeVersion
+	}
+
+	capabilities.APIVersions = append(capabilities.APIVersions, image.APIVersions...)
+
+	return capabilities, nil
+}
+
+// readCapabilities reads the capabilities either from a YAML of Capabilities
+// or from the output of `kubectl api-versions` listing an api version per line.
+func readCapabilities(capabilitiesFile string) (Capabilities, error) {
+	content, err := os.ReadFile(capabilitiesFile)
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	var capabilities Capabilities
+	if err = yaml.Unmarshal(content, &capabilities); err == nil && (len(capabilities.KubeVersion) != 0 || len(capabilities.APIVersions) != 0) {
+		return capabilities, nil
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.ContainsAny(line, " \t:") {
+			return Capabilities{}, &imgErrors.ImageError{
+				Message: fmt.Sprintf("capabilities file '%s' should either be a YAML of kubeVersion and apiVersions "+
+					"or list an api version per line, found '%s'", capabilitiesFile, line),
+			}
+		}
+
+		capabilities.APIVersions = append(capabilities.APIVersions, line)
+	}
+
+	return capabilities, nil
+}
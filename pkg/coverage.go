@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/nikhilsbhat/common/renderer"
+	"github.com/nikhilsbhat/helm-images/pkg/k8s"
+)
+
+// imageFieldRegex matches the keys those usually hold images, ex: image, baseImage, initImage, imageName.
+var imageFieldRegex = regexp.MustCompile(`(?i)image(name|url)?$`)
+
+// addSkipped records the manifest skipped for its kind, when the coverage report is enabled.
+func (image *Images) addSkipped(currentKind, currentManifestName, kubeKindTemplate string) {
+	if !image.Coverage || len(currentKind) == 0 {
+		return
+	}
+
+	image.skipped = append(image.skipped, k8s.Skipped{
+		Kind:        currentKind,
+		Name:        currentManifestName,
+		ImageFields: getImageFields(kubeKindTemplate),
+	})
+}
+
+// renderCoverage renders the coverage report as a table to stderr, so that it does not get mixed with the images listed in stdout.
+// It is used for the formats that cannot carry the report along with the images.
+func (image *Images) renderCoverage(skipped []k8s.Skipped) error {
+	if !image.Coverage {
+		return nil
+	}
+
+	if len(skipped) == 0 {
+		image.log.Info("coverage: no manifests were skipped for their kind")
+
+		return nil
+	}
+
+	coverageTable := [][]string{{"Kind", "Name", "Image Fields"}}
+	for _, skip := range skipped {
+		coverageTable = append(coverageTable, []string{skip.Kind, skip.Name, fmt.Sprintf("%v", skip.ImageFields)})
+	}
+
+	coverageRenderer := renderer.GetRenderer(os.Stderr, image.log, image.NoColor, false, false, false, true)
+
+	return coverageRenderer.Render(coverageTable)
+}
+
+// getImageFields returns the sorted paths of the fields of the manifest, the keys of which look like they hold images.
+func getImageFields(kubeKindTemplate string) []string {
+	var manifest map[string]any
+	if err := yaml.Unmarshal([]byte(kubeKindTemplate), &manifest); err != nil {
+		return nil
+	}
+
+	fields := make([]string, 0)
+	collectImageFields(manifest, "", &fields)
+
+	sort.Strings(fields)
+
+	return fields
+}
+
+func collectImageFields(value any, path string, fields *[]string) {
+	switch valueType := value.(type) {
+	case map[string]any:
+		for key, nested := range valueType {
+			nestedPath := key
+			if len(path) != 0 {
+				nestedPath = path + "." + key
+			}
+
+			if strValue, ok := nested.(string); ok && len(strValue) != 0 && imageFieldRegex.MatchString(key) {
+				*fields = append(*fields, nestedPath)
+			}
+
+			collectImageFields(nested, nestedPath, fields)
+		}
+	case []any:
+		for index, item := range valueType {
+			collectImageFields(item, fmt.Sprintf("%s[%d]", path, index), fields)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// buildDependencies fetches the dependencies of the chart, same as `helm dependency build`.
+func (image *Images) buildDependencies(chartPath string) error {
+	chartRequested, err := loader.Load(chartPath)
+	if err != nil {
+		return err
+	}
+
+	if len(chartRequested.Metadata.Dependencies) == 0 {
+		return nil
+	}
+
+	image.log.Debugf("building dependencies of the chart at '%s'", chartPath)
+
+	manager, err := image.newDependencyManager(chartPath)
+	if err != nil {
+		return err
+	}
+
+	if err = manager.Build(); err != nil {
+		return fmt.Errorf("building dependencies of the chart at '%s' errored with: %w", chartPath, err)
+	}
+
+	return nil
+}
+
+// updateDependencies fetches the dependencies of the chart afresh as per Chart.yaml, same as `helm dependency update`.
+func (image *Images) updateDependencies(chartPath string) error {
+	image.log.Debugf("updating dependencies of the chart at '%s'", chartPath)
+
+	manager, err := image.newDependencyManager(chartPath)
+	if err != nil {
+		return err
+	}
+
+	if err = manager.Update(); err != nil {
+		return fmt.Errorf("updating dependencies of the chart at '%s' errored with: %w", chartPath, err)
+	}
+
+	return nil
+}
+
+func (image *Images) newDependencyManager(chartPath string) (*downloader.Manager, error) {
+	settings := cli.New()
+
+	registryClient, err := image.newRegistryClient(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &downloader.Manager{
+		Out:              io.Discard,
+		ChartPath:        chartPath,
+		Getters:          getter.All(settings),
+		RegistryClient:   registryClient,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}, nil
+}
@@ -0,0 +1,181 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nikhilsbhat/helm-images/pkg/k8s"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const (
+	toggleKey      = "enabled"
+	toggleTagsKey  = "tags"
+	toggleEscapees = `\.,=[]`
+)
+
+// getOptionalImages renders the chart once more with all of its optional components enabled, and returns the images along with the ones
+// that appear only then, marked as ImageTypeOptional. Failing to render so is reported as a warning, as enabling everything at once
+// might not always be a valid combination.
+func (image *Images) getOptionalImages(ctx context.Context, images []*k8s.Image, warnings []k8s.Warning) ([]*k8s.Image, []k8s.Warning, error) {
+	toggles, err := image.getChartToggles(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(toggles) == 0 {
+		image.log.Infof("the chart '%s' does not have any optional components to enable", image.chart)
+
+		return images, warnings, nil
+	}
+
+	image.log.Infof("rendering chart '%s' with the optional components enabled: %s", image.chart, strings.Join(toggles, ", "))
+
+	originalValues := image.Values
+	originalSkipped := image.skipped
+
+	defer func() {
+		image.Values = originalValues
+		image.skipped = originalSkipped
+	}()
+
+	image.Values = slices.Clone(originalValues)
+	for _, toggle := range toggles {
+		image.Values = append(image.Values, toggle+"=true")
+	}
+
+	manifests, err := image.getChartFromTemplate(ctx)
+	if err != nil {
+		if err = image.addWarning(&warnings, k8s.Warning{
+			Kind:    kindChart,
+			Name:    image.chart,
+			Message: fmt.Sprintf("rendering chart with the optional components enabled errored with '%v'", err),
+		}); err != nil {
+			return nil, nil, err
+		}
+
+		return images, warnings, nil
+	}
+
+	exhaustiveImages, exhaustiveWarnings, err := image.getImagesFromManifests(image.GetTemplates(manifests), image.namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, warning := range exhaustiveWarnings {
+		if !slices.Contains(warnings, warning) {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	return append(images, getImagesOnlyIn(exhaustiveImages, images)...), warnings, nil
+}
+
+// getImagesOnlyIn returns the images from exhaustiveImages missing in images, marked as ImageTypeOptional.
+func getImagesOnlyIn(exhaustiveImages, images []*k8s.Image) []*k8s.Image {
+	known := GetImagesFromKind(images)
+	optionalImages := make([]*k8s.Image, 0)
+
+	for _, exhaustiveImage := range exhaustiveImages {
+		imageNames := slices.DeleteFunc(slices.Clone(exhaustiveImage.Image), func(imageName string) bool {
+			return slices.Contains(known, imageName)
+		})
+
+		if len(imageNames) == 0 {
+			continue
+		}
+
+		optionalImage := *exhaustiveImage
+		optionalImage.Image = imageNames
+		optionalImage.Type = k8s.ImageTypeOptional
+		optionalImages = append(optionalImages, &optionalImage)
+	}
+
+	return optionalImages
+}
+
+// getChartToggles loads the chart and returns the paths of the toggles that enable its optional components.
+func (image *Images) getChartToggles(ctx context.Context) ([]string, error) {
+	chartRequested, err := image.loadChart(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	toggles := make(map[string]bool)
+	collectChartToggles(chartRequested, nil, toggles)
+
+	paths := make([]string, 0, len(toggles))
+	for path := range toggles {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// collectChartToggles collects the `enabled` flags set false in values.yaml of the chart and of its subcharts,
+// along with the conditions and tags of its dependencies from Chart.yaml. Paths of the subcharts are prefixed by the name (or alias) of them.
+func collectChartToggles(chrt *chart.Chart, prefix []string, toggles map[string]bool) {
+	collectValuesToggles(chrt.Values, prefix, toggles)
+
+	for _, dependency := range chrt.Metadata.Dependencies {
+		for _, condition := range strings.Split(dependency.Condition, ",") {
+			if condition = strings.TrimSpace(condition); len(condition) != 0 {
+				toggles[getTogglePath(append(slices.Clone(prefix), strings.Split(condition, ".")...))] = true
+			}
+		}
+
+		for _, tag := range dependency.Tags {
+			toggles[getTogglePath([]string{toggleTagsKey, tag})] = true
+		}
+
+		name := dependency.Name
+		if len(dependency.Alias) != 0 {
+			name = dependency.Alias
+		}
+
+		for _, subChart := range chrt.Dependencies() {
+			if subChart.Name() == dependency.Name {
+				collectChartToggles(subChart, append(slices.Clone(prefix), name), toggles)
+			}
+		}
+	}
+}
+
+func collectValuesToggles(values map[string]interface{}, path []string, toggles map[string]bool) {
+	for key, value := range values {
+		switch currentValue := value.(type) {
+		case map[string]interface{}:
+			collectValuesToggles(currentValue, append(slices.Clone(path), key), toggles)
+		case bool:
+			if key == toggleKey && !currentValue {
+				toggles[getTogglePath(append(slices.Clone(path), key))] = true
+			}
+		}
+	}
+}
+
+// getTogglePath joins the keys to the path that could be set by --set, escaping the characters --set treats specially.
+func getTogglePath(keys []string) string {
+	escapedKeys := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		var escapedKey strings.Builder
+
+		for _, char := range key {
+			if strings.ContainsRune(toggleEscapees, char) {
+				escapedKey.WriteRune('\\')
+			}
+
+			escapedKey.WriteRune(char)
+		}
+
+		escapedKeys = append(escapedKeys, escapedKey.String())
+	}
+
+	return strings.Join(escapedKeys, ".")
+}
@@ -3,18 +3,36 @@ package pkg
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	imageErr "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"helm.sh/helm/v3/pkg/ignore"
 )
 
+const (
+	packagedChartExt = ".tgz"
+	chartFileName    = "Chart.yaml"
+	// DefaultChartsDirDepth is the default depth the charts are discovered up to under the charts directory, only the ones directly under it.
+	DefaultChartsDirDepth = 1
+)
+
+// chartInfo is a chart discovered under the charts directory, named by its path relative to the directory
+// and released by the name of its directory, or of the packaged chart without the extension.
 type chartInfo struct {
-	name string
-	path string
+	name     string
+	release  string
+	path     string
+	packaged bool
 }
 
-// getChartsFromDir discovers all helm charts in the specified directory.
+// getChartsFromDir discovers all helm charts in the specified directory, along with the packaged ones, up to ChartsDirDepth deep
+// (DefaultChartsDirDepth when not set, and without any limit when negative).
+// Directories and charts matching ChartsDirExclude are skipped, and when ChartsDirInclude is set only the charts matching it (or under the
+// directories matching it) are discovered. Nothing under a chart is discovered, neither its vendored subcharts nor the packaged files it ships,
+// as they are rendered along with it.
 func (image *Images) getChartsFromDir() ([]chartInfo, error) {
 	chartsDir := image.ChartsDir
 	image.log.Debugf("scanning directory '%s' for helm charts", chartsDir)
@@ -33,31 +51,88 @@ func (image *Images) getChartsFromDir() ([]chartInfo, error) {
 		return nil, &imageErr.ImageError{Message: fmt.Sprintf("'%s' is not a directory", chartsDir)}
 	}
 
-	charts := make([]chartInfo, 0)
+	excludeRules, err := ignore.Parse(strings.NewReader(strings.Join(image.ChartsDirExclude, "\n")))
+	if err != nil {
+		return nil, &imageErr.ImageError{Message: fmt.Sprintf("parsing patterns to exclude charts errored with '%v'", err)}
+	}
 
-	// Read directory entries
-	entries, err := os.ReadDir(chartsDir)
+	includeRules, err := ignore.Parse(strings.NewReader(strings.Join(image.ChartsDirInclude, "\n")))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read directory '%s': %w", chartsDir, err)
+		return nil, &imageErr.ImageError{Message: fmt.Sprintf("parsing patterns to include charts errored with '%v'", err)}
+	}
+
+	maxDepth := image.ChartsDirDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultChartsDirDepth
 	}
 
-	// Look for Chart.yaml in each subdirectory
-	for _, entry := range entries {
+	charts := make([]chartInfo, 0)
+
+	// Look for Chart.yaml in the subdirectories, along with the packaged charts
+	err = filepath.WalkDir(chartsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read directory '%s': %w", path, err)
+		}
+
+		relativePath, err := filepath.Rel(chartsDir, path)
+		if err != nil {
+			return err
+		}
+
+		if relativePath == "." {
+			return nil
+		}
+
+		relativePath = filepath.ToSlash(relativePath)
+		depth := strings.Count(relativePath, "/") + 1
+
+		entryInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if excludeRules.Ignore(relativePath, entryInfo) {
+			image.log.Debugf("skipping '%s' as it matches the patterns to exclude", path)
+
+			return skipEntry(entry)
+		}
+
 		if !entry.IsDir() {
-			continue
+			if filepath.Ext(entry.Name()) == packagedChartExt && image.isChartIncluded(includeRules, relativePath) {
+				image.log.Debugf("discovered packaged helm chart: %s at %s", entry.Name(), filepath.Dir(path))
+				charts = append(charts, chartInfo{
+					name:     strings.TrimSuffix(relativePath, packagedChartExt),
+					release:  strings.TrimSuffix(entry.Name(), packagedChartExt),
+					path:     path,
+					packaged: true,
+				})
+			}
+
+			return nil
 		}
 
-		chartPath := filepath.Join(chartsDir, entry.Name())
-		chartYamlPath := filepath.Join(chartPath, "Chart.yaml")
+		// Check if Chart.yaml exists, the files under the chart are rendered along with it.
+		if isChartDir(path) {
+			if image.isChartIncluded(includeRules, relativePath) {
+				image.log.Debugf("discovered helm chart: %s at %s", entry.Name(), path)
+				charts = append(charts, chartInfo{
+					name:    relativePath,
+					release: entry.Name(),
+					path:    path,
+				})
+			}
+
+			return filepath.SkipDir
+		}
 
-		// Check if Chart.yaml exists
-		if _, err := os.Stat(chartYamlPath); err == nil {
-			image.log.Debugf("discovered helm chart: %s at %s", entry.Name(), chartPath)
-			charts = append(charts, chartInfo{
-				name: entry.Name(),
-				path: chartPath,
-			})
+		if maxDepth > 0 && depth >= maxDepth {
+			return filepath.SkipDir
 		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if len(charts) == 0 {
@@ -69,21 +144,66 @@ func (image *Images) getChartsFromDir() ([]chartInfo, error) {
 	return charts, nil
 }
 
+// isChartIncluded returns true when the chart, or one of the directories it is under, matches the patterns to include.
+// All the charts are included when there are no patterns to include.
+func (image *Images) isChartIncluded(includeRules *ignore.Rules, relativePath string) bool {
+	if len(image.ChartsDirInclude) == 0 {
+		return true
+	}
+
+	for path := relativePath; path != "."; path = filepath.ToSlash(filepath.Dir(path)) {
+		info, err := os.Stat(filepath.Join(image.ChartsDir, path))
+		if err == nil && includeRules.Ignore(path, info) {
+			return true
+		}
+	}
+
+	image.log.Debugf("skipping chart '%s' as it does not match the patterns to include", relativePath)
+
+	return false
+}
+
+func isChartDir(path string) bool {
+	_, err := os.Stat(filepath.Join(path, chartFileName))
+
+	return err == nil
+}
+
+func skipEntry(entry fs.DirEntry) error {
+	if entry.IsDir() {
+		return filepath.SkipDir
+	}
+
+	return nil
+}
+
 // getChartManifestFromDir renders a single chart from the charts directory.
-func (image *Images) getChartManifestFromDir(ctx context.Context, chartPath, chartName string) ([]byte, error) {
-	image.log.Debugf("rendering helm chart from path '%s'", chartPath)
+func (image *Images) getChartManifestFromDir(ctx context.Context, chart chartInfo) ([]byte, error) {
+	image.log.Debugf("rendering helm chart from path '%s'", chart.path)
+
+	defer image.useChartFromDir(chart)()
+
+	// Use existing template rendering logic
+	return image.getChartFromTemplate(ctx)
+}
 
-	// Temporarily set the chart path and release name
+// useChartFromDir temporarily sets the chart path and release name to the ones of the chart from the charts directory, or to DefaultReleaseName when set,
+// and returns the func restoring them. Only the packaged charts are verified with --verify, as the unpacked ones do not have a provenance file.
+func (image *Images) useChartFromDir(chart chartInfo) func() {
 	originalChart := image.chart
 	originalRelease := image.release
-	image.chart = chartPath
-	image.release = chartName
+	originalVerify := image.Verify
+	image.chart = chart.path
+	image.release = chart.release
+	if len(image.DefaultReleaseName) != 0 {
+		image.release = image.DefaultReleaseName
+	}
+
+	image.Verify = originalVerify && chart.packaged
 
-	defer func() {
+	return func() {
 		image.chart = originalChart
 		image.release = originalRelease
-	}()
-
-	// Use existing template rendering logic
-	return image.getChartFromTemplate(ctx)
+		image.Verify = originalVerify
+	}
 }
@@ -0,0 +1,129 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	imageError "github.com/nikhilsbhat/helm-images/pkg/errors"
+)
+
+const (
+	gitChartPrefix = "git+"
+	gitRefQuery    = "ref"
+)
+
+// gitSchemes are the schemes the git repositories of the charts could be cloned with, others like ext:: could run arbitrary commands.
+var gitSchemes = []string{"https", "ssh", "file"}
+
+// gitChart holds the chart located in a git repository, referred as git+<repository>//<path>?ref=<ref>.
+// ex: git+https://github.com/org/repo//charts/sample?ref=v1.2.0 or git+file:///path/to/repo//charts/sample.
+type gitChart struct {
+	repository string
+	path       string
+	ref        string
+}
+
+func isGitChart(chart string) bool {
+	return strings.HasPrefix(chart, gitChartPrefix)
+}
+
+func parseGitChart(chart string) (*gitChart, error) {
+	chartURL, err := url.Parse(strings.TrimPrefix(chart, gitChartPrefix))
+	if err != nil {
+		return nil, &imageError.ImageError{Message: fmt.Sprintf("parsing git chart '%s' errored with '%v'", chart, err)}
+	}
+
+	if !slices.Contains(gitSchemes, chartURL.Scheme) {
+		return nil, &imageError.ImageError{
+			Message: fmt.Sprintf("scheme of git chart '%s' is not supported, it should be one of %s", chart, strings.Join(gitSchemes, "|")),
+		}
+	}
+
+	ref := chartURL.Query().Get(gitRefQuery)
+	repositoryPath, chartPath, _ := strings.Cut(chartURL.Path, "//")
+
+	chartURL.Path = repositoryPath
+	chartURL.RawQuery = ""
+
+	// The repository is passed to git after '--', while the ref is not, and git passes the user and host on to ssh.
+	// Values starting with '-' would be parsed as options by those, ex: ssh://-oProxyCommand=<command>/repo running arbitrary commands.
+	if strings.HasPrefix(chartURL.User.Username(), "-") || strings.HasPrefix(chartURL.Host, "-") || strings.HasPrefix(ref, "-") {
+		return nil, &imageError.ImageError{Message: fmt.Sprintf("user, host and ref of git chart '%s' should not start with '-'", chart)}
+	}
+
+	return &gitChart{repository: chartURL.String(), path: chartPath, ref: ref}, nil
+}
+
+// checkoutGitChart clones the git repository of the chart, checks out the ref and builds the dependencies of the chart.
+// It returns the path to the chart along with the func to clean up the clone.
+func (image *Images) checkoutGitChart(ctx context.Context) (string, func(), error) {
+	chart, err := parseGitChart(image.chart)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cloneDir, err := os.MkdirTemp("", "helm-images-git-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	cleanup := func() {
+		if err := os.RemoveAll(cloneDir); err != nil {
+			image.log.Warnf("removing the clone of '%s' at '%s' errored with '%v'", chart.repository, cloneDir, err)
+		}
+	}
+
+	chartPath, err := image.cloneGitChart(ctx, chart, cloneDir)
+	if err != nil {
+		cleanup()
+
+		return "", nil, err
+	}
+
+	return chartPath, cleanup, nil
+}
+
+func (image *Images) cloneGitChart(ctx context.Context, chart *gitChart, cloneDir string) (string, error) {
+	image.log.Debugf("cloning git repository '%s' to '%s'", chart.repository, cloneDir)
+
+	if err := runGit(ctx, "clone", "--quiet", "--", chart.repository, cloneDir); err != nil {
+		return "", err
+	}
+
+	if len(chart.ref) != 0 {
+		image.log.Debugf("checking out ref '%s' of git repository '%s'", chart.ref, chart.repository)
+
+		// The ref is followed by '--', so that it is never taken for a path.
+		if err := runGit(ctx, "-C", cloneDir, "checkout", "--quiet", chart.ref, "--"); err != nil {
+			return "", err
+		}
+	}
+
+	chartPath := filepath.Join(cloneDir, filepath.FromSlash(chart.path))
+	relativePath, err := filepath.Rel(cloneDir, chartPath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", &imageError.ImageError{Message: fmt.Sprintf("path '%s' of the chart is outside the git repository '%s'", chart.path, chart.repository)}
+	}
+
+	if err := image.buildDependencies(chartPath); err != nil {
+		return "", err
+	}
+
+	return chartPath, nil
+}
+
+func runGit(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("running 'git %s' errored with: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
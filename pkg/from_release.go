@@ -17,7 +17,7 @@ func (image *Images) getChartFromRelease() ([]byte, error) {
 
 	actionConfig := new(action.Configuration)
 
-	if err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
+	if err := actionConfig.Init(settings.RESTClientGetter(), image.getReleaseNamespace(settings), os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
 		image.log.Error("oops initialising helm client errored with", err)
 
 		return nil, err
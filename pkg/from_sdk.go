@@ -0,0 +1,228 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	imageError "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+const (
+	// RenderModeSDK renders the charts in-process with the helm SDK, the same way `helm template` does.
+	RenderModeSDK = "sdk"
+	// RenderModeExec renders the charts by invoking `helm template` of the helm binary set under HELM_BIN.
+	RenderModeExec = "exec"
+)
+
+var manifestNameRegex = regexp.MustCompile("# Source: [^/]+/(.+)")
+
+// getChartFromSDK renders the chart in-process by a client-only dry-run install, mirroring `helm template`.
+func (image *Images) getChartFromSDK(ctx context.Context) ([]byte, error) {
+	settings := cli.New()
+	actionConfig := new(action.Configuration)
+
+	if image.Validate {
+		if err := actionConfig.Init(settings.RESTClientGetter(), image.getReleaseNamespace(settings), os.Getenv("HELM_DRIVER"), image.log.Debugf); err != nil {
+			image.log.Error("oops initialising helm client errored with", err)
+
+			return nil, err
+		}
+	}
+
+	actionConfig.Log = image.log.Debugf
+
+	client := action.NewInstall(actionConfig)
+	client.DryRun = true
+	client.DryRunOption = "true"
+	client.ReleaseName = image.release
+	client.Replace = true
+	client.ClientOnly = !image.Validate
+	client.SkipCRDs = image.SkipCRDS
+	client.IncludeCRDs = image.IncludeCRDs
+	client.DisableHooks = image.NoHooks
+	client.IsUpgrade = image.IsUpgrade
+	client.Namespace = image.getReleaseNamespace(settings)
+	image.setChartPathOptions(&client.ChartPathOptions)
+
+	capabilities, err := image.getCapabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(capabilities.KubeVersion) != 0 {
+		kubeVersion, err := chartutil.ParseKubeVersion(capabilities.KubeVersion)
+		if err != nil {
+			return nil, &imageError.ImageError{Message: fmt.Sprintf("invalid kube version '%s': %v", capabilities.KubeVersion, err)}
+		}
+
+		client.KubeVersion = kubeVersion
+	}
+
+	client.APIVersions = chartutil.VersionSet(capabilities.APIVersions)
+
+	if len(image.PostRenderer) != 0 {
+		if client.PostRenderer, err = postrender.NewExec(image.PostRenderer, image.PostRendererArgs...); err != nil {
+			return nil, err
+		}
+	}
+
+	registryClient, err := image.newRegistryClient(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	client.SetRegistryClient(registryClient)
+
+	chartPath, err := client.LocateChart(image.chart, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	image.log.Debugf("rendering helm chart '%s' located at '%s' in-process", image.chart, chartPath)
+
+	chartRequested, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if req := chartRequested.Metadata.Dependencies; req != nil {
+		if err = action.CheckDependencies(chartRequested, req); err != nil {
+			if !image.DependencyUpdate {
+				return nil, fmt.Errorf("checking dependencies of chart '%s' errored, run 'helm dependency build' "+
+					"or set --dependency-update to fetch them: %w", image.chart, err)
+			}
+
+			if err = image.updateDependencies(chartPath); err != nil {
+				return nil, err
+			}
+
+			// Reload the chart with the dependencies fetched.
+			if chartRequested, err = loader.Load(chartPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	valueOpts := &values.Options{
+		ValueFiles:    image.ValueFiles,
+		StringValues:  image.StringValues,
+		Values:        image.Values,
+		FileValues:    image.FileValues,
+		JSONValues:    image.JSONValues,
+		LiteralValues: image.LiteralValues,
+	}
+
+	vals, err := valueOpts.MergeValues(getter.All(settings))
+	if err != nil {
+		return nil, err
+	}
+
+	var helmRelease *release.Release
+
+	if len(image.LookupObjects) != 0 {
+		helmRelease, err = image.renderWithLookup(ctx, actionConfig, client, chartRequested, vals)
+	} else {
+		helmRelease, err = client.RunWithContext(ctx, chartRequested, vals)
+	}
+
+	if err != nil {
+		image.log.Errorf("rendering template for release: '%s' errored with %v", image.release, err)
+
+		return nil, err
+	}
+
+	manifests := image.getReleaseManifests(helmRelease)
+
+	if len(image.ShowOnly) != 0 {
+		return filterManifests(manifests, image.ShowOnly)
+	}
+
+	return manifests, nil
+}
+
+// getReleaseNamespace returns the namespace the release is rendered for, the one set under Namespace or else the one from helm settings (HELM_NAMESPACE).
+func (image *Images) getReleaseNamespace(settings *cli.EnvSettings) string {
+	if len(image.Namespace) != 0 {
+		return image.Namespace
+	}
+
+	return settings.Namespace()
+}
+
+// getReleaseManifests returns the manifests of the release along with its hooks, as `helm template` does.
+func (image *Images) getReleaseManifests(helmRelease *release.Release) []byte {
+	var manifests bytes.Buffer
+
+	_, _ = fmt.Fprintln(&manifests, strings.TrimSpace(helmRelease.Manifest))
+
+	if image.NoHooks {
+		return manifests.Bytes()
+	}
+
+	for _, hook := range helmRelease.Hooks {
+		if image.SkipTests && slices.Contains(hook.Events, release.HookTest) {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(&manifests, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+	}
+
+	return manifests.Bytes()
+}
+
+// filterManifests retains only the manifests rendered from the templates matching showOnly, same as `helm template --show-only`.
+func filterManifests(manifests []byte, showOnly []string) ([]byte, error) {
+	splitManifests := releaseutil.SplitManifests(string(manifests))
+
+	manifestsKeys := make([]string, 0, len(splitManifests))
+	for key := range splitManifests {
+		manifestsKeys = append(manifestsKeys, key)
+	}
+
+	sort.Sort(releaseutil.BySplitManifestsOrder(manifestsKeys))
+
+	var filtered bytes.Buffer
+
+	for _, file := range showOnly {
+		missing := true
+		file = filepath.ToSlash(file)
+
+		for _, manifestKey := range manifestsKeys {
+			manifest := splitManifests[manifestKey]
+
+			submatch := manifestNameRegex.FindStringSubmatch(manifest)
+			if len(submatch) == 0 {
+				continue
+			}
+
+			if matched, _ := filepath.Match(file, submatch[1]); !matched {
+				continue
+			}
+
+			_, _ = fmt.Fprintf(&filtered, "---\n%s\n", manifest)
+			missing = false
+		}
+
+		if missing {
+			return nil, fmt.Errorf("could not find template %s in chart", file)
+		}
+	}
+
+	return filtered.Bytes(), nil
+}
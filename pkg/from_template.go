@@ -12,10 +12,106 @@ import (
 
 	imageError "github.com/nikhilsbhat/helm-images/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/cli"
 )
 
-// getChartFromTemplate should get the manifests by rendering the helm template.
+// getChartFromTemplate should get the manifests by rendering the helm template, either in-process or with the helm binary
+// based on the RenderMode set. The manifests are read from the cache when cached already, see useCache.
 func (image *Images) getChartFromTemplate(ctx context.Context) ([]byte, error) {
+	if isGitChart(image.chart) {
+		chartPath, cleanup, err := image.checkoutGitChart(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		defer cleanup()
+
+		originalChart := image.chart
+		image.chart = chartPath
+
+		defer func() {
+			image.chart = originalChart
+		}()
+	}
+
+	if image.Verify {
+		chartPath, err := image.verifyChart()
+		if err != nil {
+			return nil, err
+		}
+
+		originalChart := image.chart
+		image.chart = chartPath
+
+		defer func() {
+			image.chart = originalChart
+		}()
+	}
+
+	var (
+		manifests    []byte
+		placeholders []string
+		err          error
+	)
+
+	if image.useCache() {
+		manifests, placeholders, err = image.renderChartFromCache(ctx)
+	} else {
+		manifests, placeholders, err = image.renderChartWithPlaceholders(ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	image.addPlaceholders(placeholders)
+
+	return manifests, nil
+}
+
+// renderChartWithPlaceholders renders the chart, with placeholders for the required values when BestEffort is set.
+func (image *Images) renderChartWithPlaceholders(ctx context.Context) ([]byte, []string, error) {
+	var (
+		manifests    []byte
+		placeholders []string
+		err          error
+	)
+
+	if image.BestEffort {
+		manifests, placeholders, err = image.renderChartBestEffort(ctx)
+	} else {
+		manifests, err = image.renderChart(ctx)
+	}
+
+	if err != nil || len(image.PostRenderer) == 0 {
+		return manifests, placeholders, err
+	}
+
+	return normalizePostRendered(manifests), placeholders, nil
+}
+
+// renderChart renders the chart either in-process or with the helm binary based on the RenderMode set.
+func (image *Images) renderChart(ctx context.Context) ([]byte, error) {
+	switch image.RenderMode {
+	case RenderModeExec:
+		if len(image.LookupObjects) != 0 {
+			return nil, &imageError.ImageError{
+				Message: fmt.Sprintf("objects for lookup are supported only with render mode '%s', as helm binary does not support them", RenderModeSDK),
+			}
+		}
+
+		return image.getChartFromHelmBin(ctx)
+	case RenderModeSDK, "":
+		return image.getChartFromSDK(ctx)
+	default:
+		return nil, &imageError.ImageError{
+			Message: fmt.Sprintf("render mode '%s' is not supported, it should be one of %s|%s", image.RenderMode, RenderModeSDK, RenderModeExec),
+		}
+	}
+}
+
+// getChartFromHelmBin should get the manifests by rendering the helm template with the helm binary.
+func (image *Images) getChartFromHelmBin(ctx context.Context) ([]byte, error) {
 	flags := make([]string, 0)
 
 	for _, value := range image.Values {
@@ -34,6 +130,14 @@ func (image *Images) getChartFromTemplate(ctx context.Context) ([]byte, error) {
 		flags = append(flags, "--set-file", fileValue)
 	}
 
+	for _, jsonValue := range image.JSONValues {
+		flags = append(flags, "--set-json", jsonValue)
+	}
+
+	for _, literalValue := range image.LiteralValues {
+		flags = append(flags, "--set-literal", literalValue)
+	}
+
 	for _, valueFile := range image.ValueFiles {
 		flags = append(flags, "--values", valueFile)
 	}
@@ -50,15 +154,70 @@ func (image *Images) getChartFromTemplate(ctx context.Context) ([]byte, error) {
 		flags = append(flags, "--skip-crds")
 	}
 
+	if image.IncludeCRDs {
+		flags = append(flags, "--include-crds")
+	}
+
+	if image.NoHooks {
+		flags = append(flags, "--no-hooks")
+	}
+
+	if image.IsUpgrade {
+		flags = append(flags, "--is-upgrade")
+	}
+
 	if image.Validate {
 		flags = append(flags, "--validate")
 	}
 
+	flags = append(flags, "--namespace", image.getReleaseNamespace(cli.New()))
+
 	if len(image.Version) != 0 {
 		flags = append(flags, "--version", image.Version)
 	}
 
-	args := []string{"template", image.release, image.chart}
+	if image.Devel {
+		flags = append(flags, "--devel")
+	}
+
+	if image.DependencyUpdate {
+		flags = append(flags, "--dependency-update")
+	}
+
+	flags = append(flags, image.getRepositoryFlags()...)
+
+	capabilities, err := image.getCapabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(capabilities.KubeVersion) != 0 {
+		flags = append(flags, "--kube-version", capabilities.KubeVersion)
+	}
+
+	for _, apiVersion := range capabilities.APIVersions {
+		flags = append(flags, "--api-versions", apiVersion)
+	}
+
+	if len(image.PostRenderer) != 0 {
+		flags = append(flags, "--post-renderer", image.PostRenderer)
+	}
+
+	for _, postRendererArg := range image.PostRendererArgs {
+		flags = append(flags, "--post-renderer-args", postRendererArg)
+	}
+
+	chartPath := image.chart
+
+	// helm template takes the password only as a flag, that the other users could read from the process list,
+	// hence the chart is located here with the password and helm template renders the located one instead.
+	if len(image.Password) != 0 {
+		if chartPath, err = image.locateChart(image.chart); err != nil {
+			return nil, err
+		}
+	}
+
+	args := []string{"template", image.release, chartPath}
 	args = append(args, flags...)
 
 	image.log.Debugf("rendering helm chart with following commands/flags '%s'", strings.Join(args, ", "))
@@ -69,7 +228,6 @@ func (image *Images) getChartFromTemplate(ctx context.Context) ([]byte, error) {
 	}
 
 	cmd := exec.CommandContext(ctx, helmBin, args...)
-	image.log.Debugf("running following command to render the helm template: %s", cmd.String())
 	output, err := cmd.Output()
 
 	var exitErr *exec.ExitError
@@ -2,19 +2,17 @@ package pkg
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"os"
 	"reflect"
 	"regexp"
-	"slices"
 	"strings"
 
 	"github.com/nikhilsbhat/common/renderer"
-	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
 	"github.com/nikhilsbhat/helm-images/pkg/k8s"
 	monitoringV1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	monitoringV1Alpha1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1alpha1"
 	"github.com/sirupsen/logrus"
-	"github.com/thoas/go-funk"
 )
 
 const (
@@ -28,43 +26,91 @@ const (
 
 // Images represents GetImages.
 type Images struct {
-	Registries          []string   `json:"registries,omitempty"              yaml:"registries,omitempty"`
-	Kind                []string   `json:"kind,omitempty"                    yaml:"kind,omitempty"`
-	Values              []string   `json:"values,omitempty"                  yaml:"values,omitempty"`
-	StringValues        []string   `json:"string_values,omitempty"           yaml:"string_values,omitempty"`
-	FileValues          []string   `json:"file_values,omitempty"             yaml:"file_values,omitempty"`
-	ShowOnly            []string   `json:"show_only,omitempty"               yaml:"show_only,omitempty"`
-	Skip                []string   `json:"skip,omitempty"                    yaml:"skip,omitempty"`
-	SkipReleases        []string   `json:"skip_releases,omitempty"           yaml:"skip_releases,omitempty"`
-	Version             string     `json:"version,omitempty"                 yaml:"version,omitempty"`
-	ImageRegex          string     `json:"image_regex,omitempty"             yaml:"image_regex,omitempty"`
-	ConfigMapImageRegex string     `json:"configmap_image_regex,omitempty"   yaml:"configmap_image_regex,omitempty"`
-	ValueFiles          ValueFiles `json:"value_files,omitempty"             yaml:"value_files,omitempty"`
-	LogLevel            string     `json:"log_level,omitempty"               yaml:"log_level,omitempty"`
-	OutputFormat        string     `json:"output_format,omitempty"           yaml:"output_format,omitempty"`
-	ChartsDir           string     `json:"charts_dir,omitempty"              yaml:"charts_dir,omitempty"`
-	Revision            int        `json:"revision,omitempty"                yaml:"revision,omitempty"`
-	Raw                 bool       `json:"raw,omitempty"                     yaml:"raw,omitempty"`
-	SkipTests           bool       `json:"skip_tests,omitempty"              yaml:"skip_tests,omitempty"`
-	SkipCRDS            bool       `json:"skip_crds,omitempty"               yaml:"skip_crds,omitempty"`
-	FromRelease         bool       `json:"from_release,omitempty"            yaml:"from_release,omitempty"`
-	UniqueImages        bool       `json:"unique_images,omitempty"           yaml:"unique_images,omitempty"`
-	NoColor             bool       `json:"no_color,omitempty"                yaml:"no_color,omitempty"`
-	Validate            bool       `json:"validate,omitempty"                yaml:"validate,omitempty"`
-	IsDefaultNamespace  bool       `json:"is_default_namespace,omitempty"    yaml:"is_default_namespace,omitempty"`
-	Quiet               bool       `json:"quiet,omitempty"                   yaml:"quiet,omitempty"`
-	releasesToSkip      []skipReleaseInfo
-	json                bool
-	yaml                bool
-	table               bool
-	csv                 bool
-	all                 bool
-	raw                 []byte
-	release             string
-	chart               string
-	namespace           string
-	log                 *logrus.Logger
-	renderer            renderer.Config
+	Registries            []string   `json:"registries,omitempty"               yaml:"registries,omitempty"`
+	Kind                  []string   `json:"kind,omitempty"                     yaml:"kind,omitempty"`
+	Values                []string   `json:"values,omitempty"                   yaml:"values,omitempty"`
+	StringValues          []string   `json:"string_values,omitempty"            yaml:"string_values,omitempty"`
+	FileValues            []string   `json:"file_values,omitempty"              yaml:"file_values,omitempty"`
+	JSONValues            []string   `json:"json_values,omitempty"              yaml:"json_values,omitempty"`
+	LiteralValues         []string   `json:"literal_values,omitempty"           yaml:"literal_values,omitempty"`
+	ShowOnly              []string   `json:"show_only,omitempty"                yaml:"show_only,omitempty"`
+	Variants              []string   `json:"variants,omitempty"                 yaml:"variants,omitempty"`
+	Skip                  []string   `json:"skip,omitempty"                     yaml:"skip,omitempty"`
+	SkipReleases          []string   `json:"skip_releases,omitempty"            yaml:"skip_releases,omitempty"`
+	Version               string     `json:"version,omitempty"                  yaml:"version,omitempty"`
+	Namespace             string     `json:"namespace,omitempty"                yaml:"namespace,omitempty"`
+	DefaultReleaseName    string     `json:"default_release_name,omitempty"     yaml:"default_release_name,omitempty"`
+	ImageRegex            string     `json:"image_regex,omitempty"              yaml:"image_regex,omitempty"`
+	ConfigMapImageRegex   string     `json:"configmap_image_regex,omitempty"    yaml:"configmap_image_regex,omitempty"`
+	InjectorsConfig       string     `json:"injectors_config,omitempty"         yaml:"injectors_config,omitempty"`
+	OperatorDefaults      string     `json:"operator_defaults,omitempty"        yaml:"operator_defaults,omitempty"`
+	ValueFiles            ValueFiles `json:"value_files,omitempty"              yaml:"value_files,omitempty"`
+	LogLevel              string     `json:"log_level,omitempty"                yaml:"log_level,omitempty"`
+	OutputFormat          string     `json:"output_format,omitempty"            yaml:"output_format,omitempty"`
+	ChartsDir             string     `json:"charts_dir,omitempty"               yaml:"charts_dir,omitempty"`
+	ChartsDirInclude      []string   `json:"charts_dir_include,omitempty"       yaml:"charts_dir_include,omitempty"`
+	ChartsDirExclude      []string   `json:"charts_dir_exclude,omitempty"       yaml:"charts_dir_exclude,omitempty"`
+	RenderMode            string     `json:"render_mode,omitempty"              yaml:"render_mode,omitempty"`
+	VariantOutput         string     `json:"variant_output,omitempty"           yaml:"variant_output,omitempty"`
+	KubeVersion           string     `json:"kube_version,omitempty"             yaml:"kube_version,omitempty"`
+	APIVersions           []string   `json:"api_versions,omitempty"             yaml:"api_versions,omitempty"`
+	LookupObjects         []string   `json:"lookup_objects,omitempty"           yaml:"lookup_objects,omitempty"`
+	CapabilitiesFile      string     `json:"capabilities_file,omitempty"        yaml:"capabilities_file,omitempty"`
+	PostRenderer          string     `json:"post_renderer,omitempty"            yaml:"post_renderer,omitempty"`
+	PostRendererArgs      []string   `json:"post_renderer_args,omitempty"       yaml:"post_renderer_args,omitempty"`
+	Repo                  string     `json:"repo,omitempty"                     yaml:"repo,omitempty"`
+	Username              string     `json:"username,omitempty"                 yaml:"username,omitempty"`
+	Password              string     `json:"-"                                  yaml:"-"`
+	CaFile                string     `json:"ca_file,omitempty"                  yaml:"ca_file,omitempty"`
+	CertFile              string     `json:"cert_file,omitempty"                yaml:"cert_file,omitempty"`
+	KeyFile               string     `json:"key_file,omitempty"                 yaml:"key_file,omitempty"`
+	RegistryConfig        string     `json:"registry_config,omitempty"          yaml:"registry_config,omitempty"`
+	Keyring               string     `json:"keyring,omitempty"                  yaml:"keyring,omitempty"`
+	CacheDir              string     `json:"cache_dir,omitempty"                yaml:"cache_dir,omitempty"`
+	Revision              int        `json:"revision,omitempty"                 yaml:"revision,omitempty"`
+	BestEffortRetries     int        `json:"best_effort_retries,omitempty"      yaml:"best_effort_retries,omitempty"`
+	ChartsDirDepth        int        `json:"charts_dir_depth,omitempty"         yaml:"charts_dir_depth,omitempty"`
+	Raw                   bool       `json:"raw,omitempty"                      yaml:"raw,omitempty"`
+	SkipTests             bool       `json:"skip_tests,omitempty"               yaml:"skip_tests,omitempty"`
+	SkipCRDS              bool       `json:"skip_crds,omitempty"                yaml:"skip_crds,omitempty"`
+	IncludeCRDs           bool       `json:"include_crds,omitempty"             yaml:"include_crds,omitempty"`
+	NoHooks               bool       `json:"no_hooks,omitempty"                 yaml:"no_hooks,omitempty"`
+	IsUpgrade             bool       `json:"is_upgrade,omitempty"               yaml:"is_upgrade,omitempty"`
+	Devel                 bool       `json:"devel,omitempty"                    yaml:"devel,omitempty"`
+	DependencyUpdate      bool       `json:"dependency_update,omitempty"        yaml:"dependency_update,omitempty"`
+	FromRelease           bool       `json:"from_release,omitempty"             yaml:"from_release,omitempty"`
+	UniqueImages          bool       `json:"unique_images,omitempty"            yaml:"unique_images,omitempty"`
+	NoColor               bool       `json:"no_color,omitempty"                 yaml:"no_color,omitempty"`
+	Validate              bool       `json:"validate,omitempty"                 yaml:"validate,omitempty"`
+	IsDefaultNamespace    bool       `json:"is_default_namespace,omitempty"     yaml:"is_default_namespace,omitempty"`
+	Quiet                 bool       `json:"quiet,omitempty"                    yaml:"quiet,omitempty"`
+	Strict                bool       `json:"strict,omitempty"                   yaml:"strict,omitempty"`
+	Coverage              bool       `json:"coverage,omitempty"                 yaml:"coverage,omitempty"`
+	DetailedOutput        bool       `json:"detailed_output,omitempty"          yaml:"detailed_output,omitempty"`
+	Exhaustive            bool       `json:"exhaustive,omitempty"               yaml:"exhaustive,omitempty"`
+	BestEffort            bool       `json:"best_effort,omitempty"              yaml:"best_effort,omitempty"`
+	NoCache               bool       `json:"no_cache,omitempty"                 yaml:"no_cache,omitempty"`
+	InsecureSkipTLSVerify bool       `json:"insecure_skip_tls_verify,omitempty" yaml:"insecure_skip_tls_verify,omitempty"`
+	PassCredentials       bool       `json:"pass_credentials,omitempty"         yaml:"pass_credentials,omitempty"`
+	PlainHTTP             bool       `json:"plain_http,omitempty"               yaml:"plain_http,omitempty"`
+	Verify                bool       `json:"verify,omitempty"                   yaml:"verify,omitempty"`
+	releasesToSkip        []skipReleaseInfo
+	injectors             []Injector
+	operatorDefaults      []k8s.OperatorDefault
+	skipped               []k8s.Skipped
+	provenance            *k8s.Provenance
+	placeholders          []string
+	json                  bool
+	yaml                  bool
+	table                 bool
+	csv                   bool
+	all                   bool
+	raw                   []byte
+	release               string
+	chart                 string
+	namespace             string
+	log                   *logrus.Logger
+	renderer              renderer.Config
 }
 
 type Skip struct {
@@ -138,70 +184,45 @@ func (image *Images) GetImages(ctx context.Context) error {
 		return err
 	}
 
-	images := make([]*k8s.Image, 0)
-	kubeKindTemplates := image.GetTemplates(chart)
-	skips := image.GetResourcesToSkip()
-
-	for _, kubeKindTemplate := range kubeKindTemplates {
-		currentManifestName, err := k8s.NewName().Get(kubeKindTemplate, image.log)
-		if err != nil {
-			return err
-		}
-
-		currentKind, err := k8s.NewKind().Get(kubeKindTemplate, image.log)
-		if err != nil {
-			return err
-		}
-
-		if !funk.Contains(image.Kind, currentKind) {
-			image.log.Debugf("either helm-images plugin does not support kind '%s' "+
-				"at the moment or manifest might not have images to filter", currentKind)
-
-			continue
-		}
-
-		shouldSkip := false
-
-		for _, skip := range skips {
-			if skip.Name == strings.ToLower(currentManifestName) && skip.Kind == strings.ToLower(currentKind) {
-				image.log.Debugf("Skipping '%s' bearing name '%s' since it is set to skip.", currentKind, currentManifestName)
-
-				shouldSkip = true
-
-				break
-			}
-		}
-
-		if shouldSkip {
-			continue
-		}
-
-		image.log.Debugf("fetching images from '%s' of kind '%s'", currentKind, currentManifestName)
+	images, warnings, err := image.getImagesFromManifests(image.GetTemplates(chart), image.namespace)
+	if err != nil {
+		return err
+	}
 
-		imagesFound, err := image.GetImage(currentKind, kubeKindTemplate)
-		if err != nil {
+	if image.Exhaustive {
+		if images, warnings, err = image.getOptionalImages(ctx, images, warnings); err != nil {
 			return err
 		}
-
-		images = append(images, imagesFound...)
 	}
 
 	if len(images) == 0 {
 		switch image.FromRelease {
 		case true:
 			image.log.Infof("the release '%s' does not have any images", image.release)
-
-			return nil
 		default:
 			image.log.Infof("the chart '%s' does not have any images", image.chart)
-
-			return nil
 		}
 	}
 
-	output := image.setOutput(images)
+	if (image.json || image.yaml) && image.isDetailedOutput() {
+		return image.renderer.Render(k8s.Images{
+			ImagesFromRelease: image.setOutput(images),
+			Warnings:          warnings,
+			Coverage:          image.skipped,
+			Provenance:        image.provenance,
+			Placeholders:      image.placeholders,
+		})
+	}
 
-	return image.renderer.Render(output)
+	if len(images) == 0 {
+		return image.renderCoverage(image.skipped)
+	}
+
+	if err = image.renderer.Render(image.setOutput(images)); err != nil {
+		return err
+	}
+
+	return image.renderCoverage(image.skipped)
 }
 
 // GetTemplates returns the split manifests fetched from one big template string fetched from `helm template`.
@@ -237,9 +258,15 @@ func (image *Images) GetResourcesToSkip() []Skip {
 }
 
 // GetImage returns []*k8s.Image from the kubernetes manifests.
+// The images injected into it are predicted as for a manifest rendered on its own, without a namespace.
+func (image *Images) GetImage(currentKind, kubeKindTemplate string) ([]*k8s.Image, error) {
+	return image.getImage(currentKind, kubeKindTemplate, injectionContext{})
+}
+
+// getImage returns []*k8s.Image from the kubernetes manifest, predicting the images injected into it within the injection context passed.
 //
 //nolint:gocognit,funlen
-func (image *Images) GetImage(currentKind, kubeKindTemplate string) ([]*k8s.Image, error) {
+func (image *Images) getImage(currentKind, kubeKindTemplate string, injection injectionContext) ([]*k8s.Image, error) {
 	var (
 		img *k8s.Image
 		err error
@@ -266,6 +293,8 @@ func (image *Images) GetImage(currentKind, kubeKindTemplate string) ([]*k8s.Imag
 		img, err = k8s.NewAlertManager().Get(kubeKindTemplate, "", image.log)
 	case monitoringV1.PrometheusesKind:
 		img, err = k8s.NewPrometheus().Get(kubeKindTemplate, "", image.log)
+	case monitoringV1Alpha1.PrometheusAgentsKind:
+		img, err = k8s.NewPrometheusAgent().Get(kubeKindTemplate, "", image.log)
 	case monitoringV1.ThanosRulerKind:
 		img, err = k8s.NewThanosRuler().Get(kubeKindTemplate, "", image.log)
 	case k8s.KindThanos:
@@ -273,38 +302,115 @@ func (image *Images) GetImage(currentKind, kubeKindTemplate string) ([]*k8s.Imag
 	case k8s.KindThanosReceiver:
 		img, err = k8s.NewThanosReceiver().Get(kubeKindTemplate, "", image.log)
 	case k8s.KindGrafana:
-		img, err = k8s.NewGrafana().Get(kubeKindTemplate, "", image.log)
+		var apiVersion string
+
+		if apiVersion, err = k8s.NewAPIVersion().Get(kubeKindTemplate, image.log); err != nil {
+			return nil, err
+		}
+
+		img, err = k8s.NewGrafanaForAPIVersion(apiVersion).Get(kubeKindTemplate, "", image.log)
 	case k8s.KindCrossPlaneProvider:
 		img, err = k8s.NewCrossPlaneProvider().Get(kubeKindTemplate, "", image.log)
 	case k8s.KindCrossPlaneConfiguration:
 		img, err = k8s.NewCrossPlaneConfiguration().Get(kubeKindTemplate, "", image.log)
 	case k8s.KindCrossPlaneFunction:
 		img, err = k8s.NewCrossPlaneFunction().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindCrossPlaneDeploymentRuntimeConfig:
+		img, err = k8s.NewCrossPlaneDeploymentRuntimeConfig().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindCrossPlaneControllerConfig:
+		img, err = k8s.NewCrossPlaneControllerConfig().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindSparkApplication:
+		img, err = k8s.NewSparkApplication().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindScheduledSparkApplication:
+		img, err = k8s.NewScheduledSparkApplication().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindRayCluster:
+		img, err = k8s.NewRayCluster().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindRayJob:
+		img, err = k8s.NewRayJob().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindRayService:
+		img, err = k8s.NewRayService().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindTFJob:
+		img, err = k8s.NewTFJob().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindPyTorchJob:
+		img, err = k8s.NewPyTorchJob().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindElasticsearch:
+		img, err = k8s.NewElasticsearch().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindKibana:
+		img, err = k8s.NewKibana().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindCNPGCluster:
+		var apiVersion string
+
+		if apiVersion, err = k8s.NewAPIVersion().Get(kubeKindTemplate, image.log); err != nil {
+			return nil, err
+		}
+
+		if !k8s.IsCNPGCluster(apiVersion) {
+			image.log.Debugf("kind '%s' of apiVersion '%s' is not a CloudNativePG cluster, skipping", currentKind, apiVersion)
+
+			currentManifestName, _, _ := image.getManifestMetadata(kubeKindTemplate)
+			image.addSkipped(currentKind, currentManifestName, kubeKindTemplate)
+
+			return nil, nil
+		}
+
+		img, err = k8s.NewCNPGCluster().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindKafka:
+		img, err = k8s.NewKafka().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindDeploymentConfig:
+		img, err = k8s.NewDeploymentConfig().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindBuildConfig:
+		img, err = k8s.NewBuildConfig().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindImageStream:
+		img, err = k8s.NewImageStream().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindVirtualMachine:
+		img, err = k8s.NewVirtualMachine().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindVirtualMachineInstance:
+		img, err = k8s.NewVirtualMachineInstance().Get(kubeKindTemplate, "", image.log)
+	case k8s.KindDataVolume:
+		img, err = k8s.NewDataVolume().Get(kubeKindTemplate, "", image.log)
 	default:
 		image.log.Debugf("kind '%s' is not supported at the moment", currentKind)
 
+		currentManifestName, _, _ := image.getManifestMetadata(kubeKindTemplate)
+		image.addSkipped(currentKind, currentManifestName, kubeKindTemplate)
+
 		return nil, nil
 	}
 
 	if err != nil {
-		var grafanaErr *imgErrors.GrafanaAPIVersionSupportError
+		return nil, err
+	}
 
-		if currentKind == k8s.KindGrafana && errors.As(err, &grafanaErr) {
-			image.log.Errorf("fetching images from Kind Grafana errored with %s", err.Error())
+	if reflect.DeepEqual(img, &k8s.Image{}) {
+		return nil, nil
+	}
 
-			return nil, nil
-		}
+	if len(img.Defaulted) != 0 {
+		image.log.Warnf("'%s' of kind '%s' does not set image for '%s', the operator default would be used",
+			img.Name, img.Kind, strings.Join(img.Defaulted, ", "))
+	}
 
+	injected, err := image.getInjectedImages(currentKind, kubeKindTemplate, injection)
+	if err != nil {
 		return nil, err
 	}
 
-	if currentKind == k8s.KindConfigMap && reflect.DeepEqual(img, &k8s.Image{}) {
-		return nil, nil
+	if injected != nil {
+		return []*k8s.Image{img, injected}, nil
 	}
 
 	return []*k8s.Image{img}, nil
 }
 
+// resolveReferences links the images across the manifests rendered together, that refer each other
+// and adds the images the operators would default to, for the resources not setting one.
+func (image *Images) resolveReferences(images []*k8s.Image) []*k8s.Image {
+	k8s.ResolveImageStreamTags(images, image.log)
+	k8s.ResolveRuntimeConfigs(images, image.log)
+
+	return append(images, k8s.ResolveDefaults(images, image.getOperatorDefaults(), image.log)...)
+}
+
 // GetImagesFromKind returns list of images from array of k8s.Image.
 func GetImagesFromKind(kinds []*k8s.Image) []string {
 	var images []string
@@ -348,19 +454,28 @@ func (image *Images) getChartManifests(ctx context.Context) ([]byte, error) {
 	return image.getChartFromTemplate(ctx)
 }
 
+// isDetailedOutput reports whether json/yaml output of get is an object listing the images along with the warnings, coverage,
+// provenance and placeholders, either as asked for by DetailedOutput or as one of those is asked for. Else just the images are listed.
+func (image *Images) isDetailedOutput() bool {
+	return image.DetailedOutput || image.Coverage || image.Verify || image.BestEffort
+}
+
 func (image *Images) isSimpleOutput() bool {
 	return !image.json && !image.yaml && !image.table && !image.csv
 }
 
 func (image *Images) renderSimpleChartOutput(ctx context.Context, charts []chartInfo) error {
 	allImages := make([]string, 0)
+	skipped := make([]k8s.Skipped, 0)
 
 	for _, chart := range charts {
-		images, err := image.collectImagesFromChart(ctx, chart)
+		images, _, err := image.collectImagesFromChart(ctx, chart)
 		if err != nil {
 			return err
 		}
 
+		skipped = append(skipped, image.skipped...)
+
 		if len(images) == 0 {
 			image.log.Infof("the chart '%s' does not have any images", chart.name)
 
@@ -376,19 +491,23 @@ func (image *Images) renderSimpleChartOutput(ctx context.Context, charts []chart
 		allImages = GetUniqEntries(allImages)
 	}
 
-	return image.renderer.Render(strings.Join(allImages, "\n"))
+	if err := image.renderer.Render(strings.Join(allImages, "\n")); err != nil {
+		return err
+	}
+
+	return image.renderCoverage(skipped)
 }
 
 func (image *Images) renderStructuredChartOutput(ctx context.Context, charts []chartInfo) error {
 	imagesFromAllCharts := make([]k8s.Images, 0)
 
 	for _, chart := range charts {
-		images, err := image.collectImagesFromChart(ctx, chart)
+		images, warnings, err := image.collectImagesFromChart(ctx, chart)
 		if err != nil {
 			return err
 		}
 
-		if len(images) == 0 {
+		if len(images) == 0 && len(warnings) == 0 && len(image.skipped) == 0 {
 			image.log.Infof("the chart '%s' does not have any images", chart.name)
 
 			continue
@@ -399,67 +518,57 @@ func (image *Images) renderStructuredChartOutput(ctx context.Context, charts []c
 		imagesFromAllCharts = append(imagesFromAllCharts, k8s.Images{
 			ImagesFromRelease: output,
 			NameSpace:         chart.name,
+			Warnings:          warnings,
+			Coverage:          image.skipped,
+			Provenance:        image.provenance,
+			Placeholders:      image.placeholders,
 		})
 	}
 
 	return image.renderer.Render(imagesFromAllCharts)
 }
 
-func (image *Images) collectImagesFromChart(ctx context.Context, chart chartInfo) ([]*k8s.Image, error) {
+func (image *Images) collectImagesFromChart(ctx context.Context, chart chartInfo) ([]*k8s.Image, []k8s.Warning, error) {
 	image.log.Debugf(fetchingImagesMessage, chart.name, chart.path)
 
-	manifest, err := image.getChartManifestFromDir(ctx, chart.path, chart.name)
-	if err != nil {
-		image.log.Errorf("failed to render chart '%s': %v", chart.name, err)
+	image.skipped = nil
+	image.provenance = nil
+	image.placeholders = nil
 
-		return nil, nil
-	}
-
-	kubeKindTemplates := image.GetTemplates(manifest)
-	skips := image.GetResourcesToSkip()
-	images := make([]*k8s.Image, 0)
-
-	for _, kubeKindTemplate := range kubeKindTemplates {
-		currentManifestName, currentKind, err := image.getManifestMetadata(kubeKindTemplate)
-		if err != nil {
-			return nil, err
-		}
-
-		if !slices.Contains(image.Kind, currentKind) {
-			image.log.Debugf("either helm-images plugin does not support kind '%s' "+
-				"at the moment or manifest might not have images to filter", currentKind)
-
-			continue
-		}
-
-		if image.shouldSkipResource(skips, currentManifestName, currentKind) {
-			image.log.Debugf("Skipping '%s' bearing name '%s' since it is set to skip.", currentKind, currentManifestName)
-
-			continue
+	manifest, err := image.getChartManifestFromDir(ctx, chart)
+	if err != nil {
+		warnings := make([]k8s.Warning, 0)
+		if err = image.addWarning(&warnings, k8s.Warning{
+			Kind:    kindChart,
+			Name:    chart.name,
+			Message: fmt.Sprintf("rendering chart errored with '%v'", err),
+		}); err != nil {
+			return nil, nil, err
 		}
 
-		image.log.Debugf("fetching images from '%s' of kind '%s'", currentKind, currentManifestName)
-
-		imagesFound, err := image.GetImage(currentKind, kubeKindTemplate)
-		if err != nil {
-			return nil, err
-		}
+		return nil, warnings, nil
+	}
 
-		images = append(images, imagesFound...)
+	images, warnings, err := image.getImagesFromManifests(image.GetTemplates(manifest), image.namespace)
+	if err != nil || !image.Exhaustive {
+		return images, warnings, err
 	}
 
-	return images, nil
+	defer image.useChartFromDir(chart)()
+
+	return image.getOptionalImages(ctx, images, warnings)
 }
 
+// getManifestMetadata returns the name and kind of the manifest, the kind is read first so that it is known even when reading the name fails.
 func (image *Images) getManifestMetadata(kubeKindTemplate string) (string, string, error) {
-	currentManifestName, err := k8s.NewName().Get(kubeKindTemplate, image.log)
+	currentKind, err := k8s.NewKind().Get(kubeKindTemplate, image.log)
 	if err != nil {
 		return "", "", err
 	}
 
-	currentKind, err := k8s.NewKind().Get(kubeKindTemplate, image.log)
+	currentManifestName, err := k8s.NewName().Get(kubeKindTemplate, image.log)
 	if err != nil {
-		return "", "", err
+		return "", currentKind, err
 	}
 
 	return currentManifestName, currentKind, nil
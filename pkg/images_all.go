@@ -6,7 +6,6 @@ import (
 
 	"github.com/nikhilsbhat/helm-images/pkg/errors"
 	"github.com/nikhilsbhat/helm-images/pkg/k8s"
-	"github.com/thoas/go-funk"
 )
 
 type skipReleaseInfo struct {
@@ -27,55 +26,12 @@ func (image *Images) GetAllImages() error {
 	for _, release := range releases {
 		image.log.Debugf("fetching the images from release '%s' of namespace '%s'", release.Name, release.Namespace)
 
-		images := make([]*k8s.Image, 0)
-		kubeKindTemplates := image.GetTemplates([]byte(release.Manifest))
-		skips := image.GetResourcesToSkip()
-
-		for _, kubeKindTemplate := range kubeKindTemplates {
-			currentManifestName, err := k8s.NewName().Get(kubeKindTemplate, image.log)
-			if err != nil {
-				return err
-			}
-
-			currentKind, err := k8s.NewKind().Get(kubeKindTemplate, image.log)
-			if err != nil {
-				return err
-			}
-
-			if !funk.Contains(image.Kind, currentKind) {
-				image.log.Debugf("either helm-images plugin does not support kind '%s' "+
-					"at the moment or manifest might not have images to filter", currentKind)
-
-				continue
-			}
-
-			shouldSkip := false
-
-			for _, skip := range skips {
-				if skip.Name == strings.ToLower(currentManifestName) && skip.Kind == strings.ToLower(currentKind) {
-					image.log.Debugf("Skipping '%s' bearing name '%s' since it is set to skip.", currentKind, currentManifestName)
-
-					shouldSkip = true
-
-					break
-				}
-			}
-
-			if shouldSkip {
-				continue
-			}
-
-			image.log.Debugf("fetching images from '%s' of kind '%s'", currentKind, currentManifestName)
-
-			imagesFound, err := image.GetImage(currentKind, kubeKindTemplate)
-			if err != nil {
-				return err
-			}
-
-			images = append(images, imagesFound...)
+		images, warnings, err := image.getImagesFromManifests(image.GetTemplates([]byte(release.Manifest)), release.Namespace)
+		if err != nil {
+			return err
 		}
 
-		if len(images) == 0 {
+		if len(images) == 0 && len(warnings) == 0 && len(image.skipped) == 0 {
 			image.log.Infof("the release '%s' of namespace '%s' does not have any images", release.Name, release.Namespace)
 
 			continue
@@ -83,7 +39,12 @@ func (image *Images) GetAllImages() error {
 
 		output := image.setOutput(images)
 
-		imagesFromAllRelease = append(imagesFromAllRelease, k8s.Images{ImagesFromRelease: output, NameSpace: release.Namespace})
+		imagesFromAllRelease = append(imagesFromAllRelease, k8s.Images{
+			ImagesFromRelease: output,
+			NameSpace:         release.Namespace,
+			Warnings:          warnings,
+			Coverage:          image.skipped,
+		})
 	}
 
 	return image.renderer.Render(imagesFromAllRelease)
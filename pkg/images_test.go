@@ -1,11 +1,32 @@
 package pkg_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/nikhilsbhat/helm-images/pkg"
 	"github.com/nikhilsbhat/helm-images/pkg/k8s"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/repo"
 )
 
 func Test_getImages(t *testing.T) {
@@ -133,3 +154,1483 @@ func TestImages_SetChart(t *testing.T) {
 		assert.Equal(t, "testChart", imageClient.GetChart())
 	})
 }
+
+func TestImages_GetImage(t *testing.T) {
+	imageClient := pkg.Images{}
+	imageClient.SetLogger("info")
+
+	t.Run("should pick the extractor based on apiVersion of Grafana", func(t *testing.T) {
+		grafanaV1Alpha1 := `apiVersion: integreatly.org/v1alpha1
+kind: Grafana
+metadata:
+  name: legacy
+spec:
+  baseImage: grafana/grafana:7.5.17
+  initImage: quay.io/grafana-operator/grafana_plugins_init:0.1.0`
+
+		grafanaV1Beta1 := `apiVersion: grafana.integreatly.org/v1beta1
+kind: Grafana
+metadata:
+  name: latest
+spec:
+  deployment:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: grafana
+              image: grafana/grafana:10.4.0`
+
+		legacy, err := imageClient.GetImage(k8s.KindGrafana, grafanaV1Alpha1)
+		require.NoError(t, err)
+		assert.Equal(t, []*k8s.Image{{
+			Kind:  k8s.KindGrafana,
+			Name:  "legacy",
+			Image: []string{"grafana/grafana:7.5.17", "quay.io/grafana-operator/grafana_plugins_init:0.1.0"},
+		}}, legacy)
+
+		latest, err := imageClient.GetImage(k8s.KindGrafana, grafanaV1Beta1)
+		require.NoError(t, err)
+		assert.Equal(t, []*k8s.Image{{Kind: k8s.KindGrafana, Name: "latest", Image: []string{"grafana/grafana:10.4.0"}}}, latest)
+	})
+}
+
+func TestImages_GetImageWithInjectors(t *testing.T) {
+	imageClient := pkg.Images{InjectorsConfig: "../example/injectors/injectors.yaml"}
+	imageClient.SetLogger("info")
+
+	require.NoError(t, imageClient.SetInjectors())
+
+	t.Run("should predict the images injected into the pods of the workload", func(t *testing.T) {
+		deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    metadata:
+      annotations:
+        linkerd.io/inject: enabled
+        config.linkerd.io/proxy-image: registry.example.com/linkerd/proxy:stable-2.14.10
+        vault.hashicorp.com/agent-inject: "true"
+    spec:
+      containers:
+        - name: app
+          image: registry.example.com/app:v1.0.0`
+
+		images, err := imageClient.GetImage(k8s.KindDeployment, deployment)
+		require.NoError(t, err)
+		require.Len(t, images, 2)
+		assert.Equal(t, &k8s.Image{
+			Kind: k8s.KindDeployment,
+			Name: "app",
+			Type: k8s.ImageTypeInjected,
+			Image: []string{
+				"registry.example.com/linkerd/proxy:stable-2.14.10",
+				"cr.l5d.io/linkerd/proxy-init:v2.2.3",
+				"hashicorp/vault:1.16.1",
+			},
+		}, images[1])
+	})
+
+	t.Run("should not predict injected images for workloads opted out", func(t *testing.T) {
+		pod := `apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  labels:
+    sidecar.istio.io/inject: "false"
+spec:
+  containers:
+    - name: app
+      image: registry.example.com/app:v1.0.0`
+
+		images, err := imageClient.GetImage(k8s.KindPod, pod)
+		require.NoError(t, err)
+		assert.Len(t, images, 1)
+	})
+}
+
+func TestImages_GetImagesIsolatesFaultyManifests(t *testing.T) {
+	manifests := `---
+# Source: sample/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: 1234
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: registry.example.com/broken:v1.0.0
+---
+# Source: sample/templates/pod.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+    - name: app
+      image: registry.example.com/app:v1.0.0
+`
+
+	newImageClient := func(strict bool) *pkg.Images {
+		imageClient := &pkg.Images{
+			Raw:          true,
+			Kind:         k8s.SupportedKinds(),
+			ImageRegex:   pkg.ImageRegex,
+			OutputFormat: "json",
+			NoColor:      true,
+			Strict:       strict,
+		}
+		imageClient.SetLogger("info")
+		imageClient.SetOutputFormats()
+		imageClient.SetRaw([]byte(manifests))
+
+		return imageClient
+	}
+
+	t.Run("should list just the images by default and proceed with the rest", func(t *testing.T) {
+		imageClient := newImageClient(false)
+
+		out := captureStdout(t, func() error {
+			imageClient.SetRenderer()
+
+			return imageClient.GetImages(context.Background())
+		})
+
+		var images []k8s.Image
+		require.NoError(t, json.Unmarshal(out, &images))
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindPod, Name: "app", Image: []string{"registry.example.com/app:v1.0.0"}}}, images)
+	})
+
+	t.Run("should report the faulty manifests under warnings with detailed output", func(t *testing.T) {
+		imageClient := newImageClient(false)
+		imageClient.DetailedOutput = true
+
+		output := getImagesOutput(t, imageClient)
+
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindPod, Name: "app", Image: []string{"registry.example.com/app:v1.0.0"}}}, output.ImagesFromRelease)
+		require.Len(t, output.Warnings, 1)
+		assert.Contains(t, output.Warnings[0].Message, "reading metadata of the manifest errored")
+	})
+
+	t.Run("should report the warnings even when none of the manifests have images with detailed output", func(t *testing.T) {
+		imageClient := newImageClient(false)
+		imageClient.DetailedOutput = true
+		imageClient.SetRaw([]byte(strings.SplitAfter(manifests, "registry.example.com/broken:v1.0.0\n")[0]))
+
+		output := getImagesOutput(t, imageClient)
+
+		assert.Empty(t, output.ImagesFromRelease)
+		require.Len(t, output.Warnings, 1)
+		assert.Contains(t, output.Warnings[0].Message, "reading metadata of the manifest errored")
+	})
+
+	t.Run("should fail on the faulty manifest when strict", func(t *testing.T) {
+		err := newImageClient(true).GetImages(context.Background())
+		assert.EqualError(t, err, "'' of kind 'Deployment' failed: reading metadata of the manifest errored with "+
+			"'failed to get name from the manifest, 'name' is not type string'")
+	})
+}
+
+// imagesOutput is the json output of GetImages.
+type imagesOutput struct {
+	ImagesFromRelease []k8s.Image     `json:"images_from_release"`
+	Warnings          []k8s.Warning   `json:"warnings"`
+	Coverage          []k8s.Skipped   `json:"coverage"`
+	Provenance        *k8s.Provenance `json:"provenance"`
+	Placeholders      []string        `json:"placeholders"`
+}
+
+// getImagesOutput lists the images of the chart, or of the raw manifests, set on the client as json and returns the parsed output.
+// The images are listed alone unless the detailed output is asked for, those are read into the same output here.
+func getImagesOutput(t *testing.T, imageClient *pkg.Images) imagesOutput {
+	t.Helper()
+
+	imageClient.OutputFormat = "json"
+	imageClient.NoColor = true
+
+	out := captureStdout(t, func() error {
+		imageClient.SetLogger("info")
+		imageClient.SetOutputFormats()
+		imageClient.SetRenderer()
+
+		return imageClient.GetImages(context.Background())
+	})
+
+	var output imagesOutput
+	if bytes.HasPrefix(bytes.TrimSpace(out), []byte("[")) {
+		require.NoError(t, json.Unmarshal(out, &output.ImagesFromRelease))
+
+		return output
+	}
+
+	require.NoError(t, json.Unmarshal(out, &output))
+
+	return output
+}
+
+// getImageNames returns the images listed in the output, in the order those are listed.
+func getImageNames(output imagesOutput) []string {
+	imageNames := make([]string, 0)
+	for _, img := range output.ImagesFromRelease {
+		imageNames = append(imageNames, img.Image...)
+	}
+
+	return imageNames
+}
+
+func captureStdout(t *testing.T, run func() error) []byte {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = writer
+
+	err = run()
+
+	os.Stdout = stdout
+
+	require.NoError(t, writer.Close())
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	return out
+}
+
+func TestImages_GetImagesWithCoverage(t *testing.T) {
+	manifests := `---
+# Source: sample/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  ports:
+    - port: 80
+---
+# Source: sample/templates/workflow.yaml
+apiVersion: argoproj.io/v1alpha1
+kind: WorkflowTemplate
+metadata:
+  name: build
+spec:
+  templates:
+    - name: build
+      container:
+        image: registry.example.com/builder:v1.0.0
+        imagePullPolicy: Always
+---
+# Source: sample/templates/cluster.yaml
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: workload
+---
+# Source: sample/templates/pod.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+    - name: app
+      image: registry.example.com/app:v1.0.0
+`
+
+	getCoverage := func(t *testing.T, kinds []string) []k8s.Skipped {
+		t.Helper()
+
+		imageClient := &pkg.Images{
+			Raw:        true,
+			Kind:       kinds,
+			ImageRegex: pkg.ImageRegex,
+			Coverage:   true,
+		}
+		imageClient.SetRaw([]byte(manifests))
+
+		return getImagesOutput(t, imageClient).Coverage
+	}
+
+	expected := []k8s.Skipped{
+		{Kind: "Service", Name: "app"},
+		{Kind: "WorkflowTemplate", Name: "build", ImageFields: []string{"spec.templates[0].container.image"}},
+		{Kind: "Cluster", Name: "workload"},
+	}
+
+	t.Run("should report the manifests skipped for their kind along with the fields looking like images", func(t *testing.T) {
+		assert.Equal(t, expected, getCoverage(t, k8s.SupportedKinds()))
+	})
+
+	t.Run("should report the manifests of the kinds set but not supported", func(t *testing.T) {
+		assert.Equal(t, expected, getCoverage(t, append(k8s.SupportedKinds(), "WorkflowTemplate")))
+	})
+}
+
+func TestImages_GetImagesRenderedWithSDK(t *testing.T) {
+	t.Run("should render the chart in-process without the helm binary", func(t *testing.T) {
+		t.Setenv("HELM_BIN", "")
+
+		imageClient := &pkg.Images{
+			Kind:       k8s.SupportedKinds(),
+			ImageRegex: pkg.ImageRegex,
+			ShowOnly:   []string{"templates/pod.yaml"},
+			RenderMode: pkg.RenderModeSDK,
+		}
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+
+		images := getImagesOutput(t, imageClient).ImagesFromRelease
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindPod, Name: "nginx", Image: []string{"nginx:1.14.2", "nginx:1.14.2"}}}, images)
+	})
+
+	t.Run("should fail when the template to be shown is not part of the chart", func(t *testing.T) {
+		imageClient := &pkg.Images{Kind: k8s.SupportedKinds(), ImageRegex: pkg.ImageRegex, ShowOnly: []string{"templates/missing.yaml"}}
+		imageClient.SetLogger("info")
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+
+		assert.EqualError(t, imageClient.GetImages(context.Background()), "could not find template templates/missing.yaml in chart")
+	})
+}
+
+func TestImages_GetImagesWithCapabilities(t *testing.T) {
+	getImages := func(t *testing.T, imageClient *pkg.Images) []k8s.Image {
+		t.Helper()
+
+		imageClient.Kind = k8s.SupportedKinds()
+		imageClient.ImageRegex = pkg.ImageRegex
+		imageClient.ShowOnly = []string{"templates/capabilities.yaml"}
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+
+		return getImagesOutput(t, imageClient).ImagesFromRelease
+	}
+
+	t.Run("should render against the capabilities set by flags", func(t *testing.T) {
+		images := getImages(t, &pkg.Images{KubeVersion: "v1.30.2", APIVersions: []string{"monitoring.coreos.com/v1"}})
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindPod, Name: "metrics-exporter", Image: []string{"ghcr.io/example/metrics-exporter:v2.0.0"}}}, images)
+	})
+
+	t.Run("should render against the capabilities loaded from the file", func(t *testing.T) {
+		images := getImages(t, &pkg.Images{KubeVersion: "v1.28.0", CapabilitiesFile: "../example/capabilities/api-versions.txt"})
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindPod, Name: "metrics-exporter", Image: []string{"ghcr.io/example/metrics-exporter:v1.0.0"}}}, images)
+	})
+}
+
+func TestImages_GetImagesWithPostRenderer(t *testing.T) {
+	t.Run("should identify the images from the output of post-renderer", func(t *testing.T) {
+		// mimics kustomize, that swaps the images and drops the comments.
+		postRenderer := filepath.Join(t.TempDir(), "post-renderer.sh")
+		require.NoError(t, os.WriteFile(postRenderer, []byte("#!/bin/sh\nsed -e \"s|image: nginx|image: $1/nginx|\" -e '/^#/d'\n"), 0o700)) //nolint:gosec
+
+		imageClient := &pkg.Images{
+			Kind:             []string{k8s.KindPod},
+			ImageRegex:       pkg.ImageRegex,
+			PostRenderer:     postRenderer,
+			PostRendererArgs: []string{"registry.example.com"},
+		}
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+
+		images := getImagesOutput(t, imageClient).ImagesFromRelease
+		assert.Contains(t, images, k8s.Image{
+			Kind:  k8s.KindPod,
+			Name:  "nginx",
+			Image: []string{"registry.example.com/nginx:1.14.2", "registry.example.com/nginx:1.14.2"},
+		})
+	})
+}
+
+func TestImages_GetImagesFromChartRepository(t *testing.T) {
+	helmHome := t.TempDir()
+	t.Setenv("HELM_CACHE_HOME", filepath.Join(helmHome, "cache"))
+	t.Setenv("HELM_CONFIG_HOME", filepath.Join(helmHome, "config"))
+	t.Setenv("HELM_DATA_HOME", filepath.Join(helmHome, "data"))
+
+	chartRepository := t.TempDir()
+
+	packager := action.NewPackage()
+	packager.Destination = chartRepository
+	_, err := packager.Run("../example/chart/sample", nil)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if username, password, ok := request.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			writer.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		http.FileServer(http.Dir(chartRepository)).ServeHTTP(writer, request)
+	}))
+	defer server.Close()
+
+	index, err := repo.IndexDirectory(chartRepository, server.URL)
+	require.NoError(t, err)
+	require.NoError(t, index.WriteFile(filepath.Join(chartRepository, "index.yaml"), 0o600))
+
+	newImageClient := func(password string) *pkg.Images {
+		imageClient := &pkg.Images{
+			Kind:         k8s.SupportedKinds(),
+			ImageRegex:   pkg.ImageRegex,
+			ShowOnly:     []string{"templates/pod.yaml"},
+			OutputFormat: "json",
+			NoColor:      true,
+			Repo:         server.URL,
+			Username:     "admin",
+			Password:     password,
+			Version:      "0.1.0",
+		}
+		imageClient.SetLogger("info")
+		imageClient.SetOutputFormats()
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("sample")
+		imageClient.SetRenderer()
+
+		return imageClient
+	}
+
+	t.Run("should render the chart located from the authenticated chart repository", func(t *testing.T) {
+		images := getImagesOutput(t, newImageClient("secret")).ImagesFromRelease
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindPod, Name: "nginx", Image: []string{"nginx:1.14.2", "nginx:1.14.2"}}}, images)
+	})
+
+	t.Run("should not pass the password to the helm binary, as other users could read it from the process list", func(t *testing.T) {
+		argsFile := filepath.Join(t.TempDir(), "args")
+		helmBin := filepath.Join(t.TempDir(), "helm")
+		require.NoError(t, os.WriteFile(helmBin, []byte(fmt.Sprintf("#!/bin/sh\nprintf '%%s\\n' \"$@\" > %s\n", argsFile)), 0o700)) //nolint:gosec
+		t.Setenv("HELM_BIN", helmBin)
+
+		imageClient := newImageClient("secret")
+		imageClient.RenderMode = pkg.RenderModeExec
+		require.NoError(t, imageClient.GetImages(context.Background()))
+
+		args, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		assert.NotContains(t, string(args), "secret")
+		assert.Contains(t, string(args), "sample-0.1.0.tgz")
+	})
+
+	t.Run("should fail when the credentials of chart repository are invalid", func(t *testing.T) {
+		err := newImageClient("invalid").GetImages(context.Background())
+		assert.ErrorContains(t, err, "401 Unauthorized")
+	})
+}
+
+func TestImages_GetImagesFromOCIRegistry(t *testing.T) {
+	helmHome := t.TempDir()
+	t.Setenv("HELM_CACHE_HOME", filepath.Join(helmHome, "cache"))
+	t.Setenv("HELM_CONFIG_HOME", filepath.Join(helmHome, "config"))
+	t.Setenv("HELM_DATA_HOME", filepath.Join(helmHome, "data"))
+
+	server := httptest.NewServer(newOCIRegistry(t, "../example/chart/sample", "admin", "secret"))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	newImageClient := func(imageClient *pkg.Images) *pkg.Images {
+		imageClient.Kind = k8s.SupportedKinds()
+		imageClient.ImageRegex = pkg.ImageRegex
+		imageClient.ShowOnly = []string{"templates/pod.yaml"}
+		imageClient.OutputFormat = "json"
+		imageClient.NoColor = true
+		imageClient.Version = "0.1.0"
+		imageClient.SetLogger("info")
+		imageClient.SetOutputFormats()
+		imageClient.SetRelease("sample")
+		imageClient.SetChart(fmt.Sprintf("oci://%s/charts/sample", host))
+		imageClient.SetRenderer()
+
+		return imageClient
+	}
+
+	getImages := func(t *testing.T, imageClient *pkg.Images) []k8s.Image {
+		t.Helper()
+
+		return getImagesOutput(t, newImageClient(imageClient)).ImagesFromRelease
+	}
+
+	expected := []k8s.Image{{Kind: k8s.KindPod, Name: "nginx", Image: []string{"nginx:1.14.2", "nginx:1.14.2"}}}
+
+	t.Run("should pull the chart from plain-http registry with the credentials set by flags", func(t *testing.T) {
+		assert.Equal(t, expected, getImages(t, &pkg.Images{PlainHTTP: true, Username: "admin", Password: "secret"}))
+	})
+
+	t.Run("should pull the chart with the credentials from the registry config", func(t *testing.T) {
+		registryConfig := filepath.Join(t.TempDir(), "config.json")
+		auth := base64.StdEncoding.EncodeToString([]byte("admin:secret"))
+		require.NoError(t, os.WriteFile(registryConfig, []byte(fmt.Sprintf(`{"auths":{%q:{"auth":%q}}}`, host, auth)), 0o600))
+
+		assert.Equal(t, expected, getImages(t, &pkg.Images{PlainHTTP: true, RegistryConfig: registryConfig}))
+	})
+
+	t.Run("should pull the chart with the credentials from the registry config handed over by helm", func(t *testing.T) {
+		registryConfig := filepath.Join(t.TempDir(), "config.json")
+		auth := base64.StdEncoding.EncodeToString([]byte("admin:secret"))
+		require.NoError(t, os.WriteFile(registryConfig, []byte(fmt.Sprintf(`{"auths":{%q:{"auth":%q}}}`, host, auth)), 0o600))
+
+		// helm consumes its own --registry-config and hands it over to the plugin as HELM_REGISTRY_CONFIG.
+		t.Setenv("HELM_REGISTRY_CONFIG", registryConfig)
+
+		assert.Equal(t, expected, getImages(t, &pkg.Images{PlainHTTP: true}))
+	})
+
+	t.Run("should fail to pull the chart without credentials", func(t *testing.T) {
+		err := newImageClient(&pkg.Images{PlainHTTP: true}).GetImages(context.Background())
+		assert.ErrorContains(t, err, "basic credential not found")
+	})
+}
+
+// newOCIRegistry returns a minimal OCI registry serving the chart as charts/sample, behind basic auth.
+func newOCIRegistry(t *testing.T, chartPath, username, password string) http.Handler {
+	t.Helper()
+
+	packager := action.NewPackage()
+	packager.Destination = t.TempDir()
+	chartArchive, err := packager.Run(chartPath, nil)
+	require.NoError(t, err)
+
+	chartContent, err := os.ReadFile(chartArchive)
+	require.NoError(t, err)
+
+	chart, err := loader.Load(chartArchive)
+	require.NoError(t, err)
+
+	configContent, err := json.Marshal(chart.Metadata)
+	require.NoError(t, err)
+
+	digest := func(content []byte) string {
+		return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+	}
+
+	blobs := map[string][]byte{digest(chartContent): chartContent, digest(configContent): configContent}
+
+	manifest, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config": map[string]any{
+			"mediaType": "application/vnd.cncf.helm.config.v1+json", "digest": digest(configContent), "size": len(configContent),
+		},
+		"layers": []map[string]any{{
+			"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip", "digest": digest(chartContent), "size": len(chartContent),
+		}},
+	})
+	require.NoError(t, err)
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if user, pass, ok := request.BasicAuth(); !ok || user != username || pass != password {
+			writer.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			writer.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		content, mediaType := []byte("{}"), "application/json"
+
+		switch {
+		case request.URL.Path == "/v2/charts/sample/manifests/0.1.0" || request.URL.Path == "/v2/charts/sample/manifests/"+digest(manifest):
+			content, mediaType = manifest, "application/vnd.oci.image.manifest.v1+json"
+		case strings.HasPrefix(request.URL.Path, "/v2/charts/sample/blobs/"):
+			blob, found := blobs[strings.TrimPrefix(request.URL.Path, "/v2/charts/sample/blobs/")]
+			if !found {
+				writer.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			content, mediaType = blob, "application/octet-stream"
+		case request.URL.Path != "/v2/":
+			writer.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		writer.Header().Set("Content-Type", mediaType)
+		writer.Header().Set("Docker-Content-Digest", digest(content))
+		writer.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+
+		if request.Method != http.MethodHead {
+			_, _ = writer.Write(content)
+		}
+	})
+}
+
+func TestImages_GetImagesFromGitRepository(t *testing.T) {
+	repository := t.TempDir()
+
+	git := func(args ...string) {
+		t.Helper()
+
+		output, err := exec.Command("git", append([]string{"-C", repository, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	chartPath := filepath.Join(repository, "charts", "sample")
+	require.NoError(t, os.CopyFS(chartPath, os.DirFS("../example/chart/sample")))
+	require.NoError(t, os.CopyFS(filepath.Join(repository, "..sample"), os.DirFS("../example/chart/sample")))
+
+	git("init", "--quiet")
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "add sample chart")
+	git("tag", "v1.2.0")
+
+	podTemplate := filepath.Join(chartPath, "templates", "pod.yaml")
+	pod, err := os.ReadFile(podTemplate)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(podTemplate, []byte(strings.ReplaceAll(string(pod), "nginx:1.14.2", "nginx:1.25.0")), 0o600))
+
+	git("commit", "--quiet", "-am", "bump nginx")
+
+	getImages := func(t *testing.T, chart string) []k8s.Image {
+		t.Helper()
+
+		imageClient := &pkg.Images{
+			Kind:       k8s.SupportedKinds(),
+			ImageRegex: pkg.ImageRegex,
+			ShowOnly:   []string{"templates/pod.yaml"},
+		}
+		imageClient.SetRelease("sample")
+		imageClient.SetChart(chart)
+
+		return getImagesOutput(t, imageClient).ImagesFromRelease
+	}
+
+	t.Run("should render the chart from the ref of git repository", func(t *testing.T) {
+		images := getImages(t, fmt.Sprintf("git+file://%s//charts/sample?ref=v1.2.0", repository))
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindPod, Name: "nginx", Image: []string{"nginx:1.14.2", "nginx:1.14.2"}}}, images)
+	})
+
+	t.Run("should render the chart from the default branch of git repository when ref is not set", func(t *testing.T) {
+		images := getImages(t, fmt.Sprintf("git+file://%s//charts/sample", repository))
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindPod, Name: "nginx", Image: []string{"nginx:1.25.0", "nginx:1.25.0"}}}, images)
+	})
+
+	t.Run("should render the chart from a directory of the git repository starting with '..'", func(t *testing.T) {
+		images := getImages(t, fmt.Sprintf("git+file://%s//..sample?ref=v1.2.0", repository))
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindPod, Name: "nginx", Image: []string{"nginx:1.14.2", "nginx:1.14.2"}}}, images)
+	})
+
+	t.Run("should not clone the git repository taken for options of git or ssh", func(t *testing.T) {
+		for chart, expected := range map[string]string{
+			"git+-u=touch${IFS}pwned//charts/sample":                                "scheme of git chart",
+			"git+ext::sh -c touch% pwned//charts/sample":                            "scheme of git chart",
+			fmt.Sprintf("git+file://%s//charts/sample?ref=-pwned", repository):      "should not start with '-'",
+			"git+ssh://-oProxyCommand=reboot/repository//charts/sample":             "should not start with '-'",
+			"git+ssh://-oProxyCommand=reboot@example.com/repository//charts/sample": "should not start with '-'",
+		} {
+			imageClient := &pkg.Images{Kind: k8s.SupportedKinds(), ImageRegex: pkg.ImageRegex}
+			imageClient.SetLogger("info")
+			imageClient.SetRelease("sample")
+			imageClient.SetChart(chart)
+
+			assert.ErrorContains(t, imageClient.GetImages(context.Background()), expected, chart)
+		}
+	})
+
+	t.Run("should not render the chart from outside the git repository", func(t *testing.T) {
+		imageClient := &pkg.Images{Kind: k8s.SupportedKinds(), ImageRegex: pkg.ImageRegex}
+		imageClient.SetLogger("info")
+		imageClient.SetRelease("sample")
+		imageClient.SetChart(fmt.Sprintf("git+file://%s//../sample", repository))
+
+		assert.ErrorContains(t, imageClient.GetImages(context.Background()), "is outside the git repository")
+	})
+}
+
+func TestImages_GetImagesWithVerify(t *testing.T) {
+	helmHome := t.TempDir()
+	t.Setenv("HELM_CACHE_HOME", filepath.Join(helmHome, "cache"))
+	t.Setenv("HELM_CONFIG_HOME", filepath.Join(helmHome, "config"))
+	t.Setenv("HELM_DATA_HOME", filepath.Join(helmHome, "data"))
+
+	keysDir := t.TempDir()
+	secretKeyring, publicKeyring := writeKeyring(t, keysDir, "signer")
+	_, otherPublicKeyring := writeKeyring(t, keysDir, "other")
+
+	chartsDir := t.TempDir()
+
+	packager := action.NewPackage()
+	packager.Destination = chartsDir
+	packager.Sign = true
+	packager.Key = "signer"
+	packager.Keyring = secretKeyring
+	signedChart, err := packager.Run("../example/chart/sample", nil)
+	require.NoError(t, err)
+
+	unsignedChart, err := os.ReadFile(signedChart)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(chartsDir, "unsigned-0.1.0.tgz"), unsignedChart, 0o600))
+
+	newImageClient := func(keyring string) *pkg.Images {
+		imageClient := &pkg.Images{
+			Kind:         k8s.SupportedKinds(),
+			ImageRegex:   pkg.ImageRegex,
+			ShowOnly:     []string{"templates/pod.yaml"},
+			OutputFormat: "json",
+			NoColor:      true,
+			Verify:       true,
+			Keyring:      keyring,
+		}
+		imageClient.SetLogger("info")
+		imageClient.SetOutputFormats()
+		imageClient.SetRelease("sample")
+		imageClient.SetChart(signedChart)
+		imageClient.SetRenderer()
+
+		return imageClient
+	}
+
+	t.Run("should record the provenance of the chart verified", func(t *testing.T) {
+		output := getImagesOutput(t, newImageClient(publicKeyring))
+		require.NotNil(t, output.Provenance)
+		assert.True(t, output.Provenance.Verified)
+		assert.Equal(t, []string{"signer (helm-images) <signer@example.com>"}, output.Provenance.SignedBy)
+		assert.NotEmpty(t, output.Provenance.Fingerprint)
+		assert.Contains(t, output.Provenance.FileHash, "sha256:")
+	})
+
+	t.Run("should fail when the chart is not signed by a key from the keyring", func(t *testing.T) {
+		err := newImageClient(otherPublicKeyring).GetImages(context.Background())
+		assert.ErrorContains(t, err, "verifying provenance of the chart")
+	})
+
+	t.Run("should verify only the packaged charts from the charts directory and report the ones failing", func(t *testing.T) {
+		require.NoError(t, os.CopyFS(filepath.Join(chartsDir, "sample"), os.DirFS("../example/chart/sample")))
+
+		out := captureStdout(t, func() error {
+			imageClient := newImageClient(publicKeyring)
+			imageClient.SetChartsDir(chartsDir)
+
+			return imageClient.GetImagesFromChartsDir(context.Background())
+		})
+
+		var images []k8s.Images
+		require.NoError(t, json.Unmarshal(out, &images))
+
+		provenances := make(map[string]*k8s.Provenance)
+		for _, chartImages := range images {
+			provenances[chartImages.NameSpace] = chartImages.Provenance
+
+			if chartImages.NameSpace == "unsigned-0.1.0" {
+				require.Len(t, chartImages.Warnings, 1)
+				assert.Contains(t, chartImages.Warnings[0].Message, "verifying provenance of the chart")
+			}
+		}
+
+		require.Len(t, provenances, 3)
+		assert.True(t, provenances["sample-0.1.0"].Verified)
+		assert.False(t, provenances["unsigned-0.1.0"].Verified)
+		assert.Nil(t, provenances["sample"])
+	})
+
+	valuesDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(valuesDir, "dev.yaml"), []byte("image:\n  tag: 1.25.0\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(valuesDir, "prod.yaml"), []byte("image:\n  tag: 1.24.0\n"), 0o600))
+
+	getUnion := func(t *testing.T, imageClient *pkg.Images) k8s.Images {
+		t.Helper()
+
+		var output k8s.Images
+
+		out := captureStdout(t, func() error {
+			imageClient.Variants = []string{"dev=" + filepath.Join(valuesDir, "dev.yaml"), "prod=" + filepath.Join(valuesDir, "prod.yaml")}
+			imageClient.SetRenderer()
+
+			return imageClient.GetImagesFromVariants(context.Background())
+		})
+		require.NoError(t, json.Unmarshal(out, &output))
+
+		return output
+	}
+
+	t.Run("should record the provenance of the chart along with the union of the variants when it is the same for all", func(t *testing.T) {
+		output := getUnion(t, newImageClient(publicKeyring))
+		require.NotNil(t, output.Provenance)
+		assert.Equal(t, []string{"signer (helm-images) <signer@example.com>"}, output.Provenance.SignedBy)
+		assert.Empty(t, output.Warnings)
+	})
+
+	t.Run("should not record the provenance of the chart along with the union when it differs across the variants", func(t *testing.T) {
+		publisherSecretKeyring, publisherPublicKeyring := writeKeyring(t, keysDir, "publisher")
+
+		keyrings := make([]byte, 0)
+		for _, keyring := range []string{publicKeyring, publisherPublicKeyring} {
+			publicKeys, err := os.ReadFile(keyring)
+			require.NoError(t, err)
+
+			keyrings = append(keyrings, publicKeys...)
+		}
+
+		keyring := filepath.Join(keysDir, "pubring.gpg")
+		require.NoError(t, os.WriteFile(keyring, keyrings, 0o600))
+
+		chartRepository := t.TempDir()
+		for _, signer := range []struct{ version, key, keyring string }{
+			{version: "0.1.0", key: "signer", keyring: secretKeyring},
+			{version: "0.2.0", key: "publisher", keyring: publisherSecretKeyring},
+		} {
+			repositoryPackager := action.NewPackage()
+			repositoryPackager.Destination = chartRepository
+			repositoryPackager.Version = signer.version
+			repositoryPackager.Sign = true
+			repositoryPackager.Key = signer.key
+			repositoryPackager.Keyring = signer.keyring
+			_, err := repositoryPackager.Run("../example/chart/sample", nil)
+			require.NoError(t, err)
+		}
+
+		// the latest version of the chart is published once the chart is pulled for the first variant.
+		var published atomic.Bool
+
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			switch {
+			case request.URL.Path == "/index.yaml" && published.Load():
+				http.ServeFile(writer, request, filepath.Join(chartRepository, "index-published.yaml"))
+			case strings.HasSuffix(request.URL.Path, ".tgz"):
+				published.Store(true)
+
+				fallthrough
+			default:
+				http.FileServer(http.Dir(chartRepository)).ServeHTTP(writer, request)
+			}
+		}))
+		defer server.Close()
+
+		index, err := repo.IndexDirectory(chartRepository, server.URL)
+		require.NoError(t, err)
+		require.NoError(t, index.WriteFile(filepath.Join(chartRepository, "index-published.yaml"), 0o600))
+
+		index.Entries["sample"] = slices.DeleteFunc(index.Entries["sample"], func(version *repo.ChartVersion) bool { return version.Version == "0.2.0" })
+		require.NoError(t, index.WriteFile(filepath.Join(chartRepository, "index.yaml"), 0o600))
+
+		imageClient := newImageClient(keyring)
+		imageClient.Repo = server.URL
+		imageClient.SetChart("sample")
+
+		output := getUnion(t, imageClient)
+		assert.Nil(t, output.Provenance)
+		require.Len(t, output.Warnings, 1)
+		assert.Contains(t, output.Warnings[0].Message, "provenance of the chart differs across the variants")
+	})
+}
+
+// writeKeyring generates a key for the signer and writes its secret and public keyrings under dir.
+func writeKeyring(t *testing.T, dir, signer string) (string, string) {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity(signer, "helm-images", signer+"@example.com", nil)
+	require.NoError(t, err)
+
+	secretKeyring := filepath.Join(dir, signer+"-secring.gpg")
+	publicKeyring := filepath.Join(dir, signer+"-pubring.gpg")
+
+	var secret, public bytes.Buffer
+	require.NoError(t, entity.SerializePrivate(&secret, nil))
+	require.NoError(t, entity.Serialize(&public))
+	require.NoError(t, os.WriteFile(secretKeyring, secret.Bytes(), 0o600))
+	require.NoError(t, os.WriteFile(publicKeyring, public.Bytes(), 0o600))
+
+	return secretKeyring, publicKeyring
+}
+
+func TestImages_GetImagesFromVariants(t *testing.T) {
+	valuesDir := t.TempDir()
+
+	variants := map[string]string{
+		"dev":  "image:\n  tag: 1.25.0\n",
+		"prod": "image:\n  tag: 1.24.0\n",
+		"ha":   "image:\n  tag: 1.24.0\n",
+	}
+	for name, values := range variants {
+		require.NoError(t, os.WriteFile(filepath.Join(valuesDir, name+".yaml"), []byte(values), 0o600))
+	}
+
+	getImages := func(t *testing.T, variantOutput string, output any) {
+		t.Helper()
+
+		out := captureStdout(t, func() error {
+			imageClient := &pkg.Images{
+				Kind:          []string{k8s.KindDeployment},
+				ImageRegex:    pkg.ImageRegex,
+				ShowOnly:      []string{"templates/deployment.yaml"},
+				OutputFormat:  "json",
+				NoColor:       true,
+				VariantOutput: variantOutput,
+				Variants: []string{
+					"dev=" + filepath.Join(valuesDir, "dev.yaml"),
+					"prod=" + filepath.Join(valuesDir, "prod.yaml"),
+					"ha=" + filepath.Join(valuesDir, "prod.yaml") + "," + filepath.Join(valuesDir, "ha.yaml"),
+				},
+			}
+			imageClient.SetLogger("info")
+			imageClient.SetOutputFormats()
+			imageClient.SetRelease("sample")
+			imageClient.SetChart("../example/chart/sample")
+			imageClient.SetRenderer()
+
+			return imageClient.GetImagesFromVariants(context.Background())
+		})
+
+		require.NoError(t, json.Unmarshal(out, output))
+	}
+
+	t.Run("should list the union of images along with the variants using each", func(t *testing.T) {
+		var images struct {
+			Images []k8s.VariantImage `json:"images_from_release"`
+		}
+		getImages(t, pkg.VariantOutputUnion, &images)
+
+		assert.Equal(t, []k8s.VariantImage{
+			{Image: "nginx:1.25.0", Variants: []string{"dev"}},
+			{Image: "nginx:1.24.0", Variants: []string{"prod", "ha"}},
+		}, images.Images)
+	})
+
+	t.Run("should list the placeholders of all the variants along with their union", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(valuesDir, "licensed.yaml"), []byte("image:\n  registry: ghcr.io\n  tag: v1\nlicense:\n  key: xyz\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(valuesDir, "unset.yaml"), []byte("license:\n  key: xyz\n"), 0o600))
+
+		out := captureStdout(t, func() error {
+			imageClient := &pkg.Images{
+				Kind:              []string{k8s.KindDeployment},
+				ImageRegex:        pkg.ImageRegex,
+				OutputFormat:      "json",
+				NoColor:           true,
+				BestEffort:        true,
+				BestEffortRetries: pkg.DefaultBestEffortRetries,
+				Variants: []string{
+					"licensed=" + filepath.Join(valuesDir, "licensed.yaml"),
+					"unset=" + filepath.Join(valuesDir, "unset.yaml"),
+				},
+			}
+			imageClient.SetLogger("info")
+			imageClient.SetOutputFormats()
+			imageClient.SetRelease("required")
+			imageClient.SetChart("../example/chart/required")
+			imageClient.SetRenderer()
+
+			return imageClient.GetImagesFromVariants(context.Background())
+		})
+
+		var images struct {
+			Images       []k8s.VariantImage `json:"images_from_release"`
+			Placeholders []string           `json:"placeholders"`
+		}
+		require.NoError(t, json.Unmarshal(out, &images))
+		assert.Equal(t, []k8s.VariantImage{
+			{Image: "ghcr.io/app:v1", Variants: []string{"licensed"}},
+			{Image: fmt.Sprintf("%[1]s/app:%[1]s", pkg.PlaceholderValue), Variants: []string{"unset"}},
+		}, images.Images)
+		assert.Equal(t, []string{"image.registry", "image.tag"}, images.Placeholders)
+	})
+
+	t.Run("should list the images of each variant separately", func(t *testing.T) {
+		var images []struct {
+			Variant string      `json:"name_space"`
+			Images  []k8s.Image `json:"images_from_release"`
+		}
+		getImages(t, pkg.VariantOutputVariant, &images)
+
+		require.Len(t, images, 3)
+		assert.Equal(t, "dev", images[0].Variant)
+		assert.Equal(t, []string{"nginx:1.25.0"}, images[0].Images[0].Image)
+		assert.Equal(t, "ha", images[2].Variant)
+		assert.Equal(t, []string{"nginx:1.24.0"}, images[2].Images[0].Image)
+	})
+
+	t.Run("should fail when the variant is not of the form name=values", func(t *testing.T) {
+		imageClient := &pkg.Images{Variants: []string{"dev"}}
+		imageClient.SetLogger("info")
+
+		assert.ErrorContains(t, imageClient.GetImagesFromVariants(context.Background()), "should be of the form name=values.yaml")
+	})
+}
+
+func TestImages_GetImagesExhaustive(t *testing.T) {
+	getImages := func(t *testing.T, exhaustive bool) []k8s.Image {
+		t.Helper()
+
+		imageClient := &pkg.Images{
+			Kind:       []string{k8s.KindDeployment},
+			ImageRegex: pkg.ImageRegex,
+			Exhaustive: exhaustive,
+		}
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+
+		return getImagesOutput(t, imageClient).ImagesFromRelease
+	}
+
+	t.Run("should list the images of the optional components enabled with exhaustive", func(t *testing.T) {
+		assert.Equal(t, []k8s.Image{
+			{Kind: k8s.KindDeployment, Name: "sample", Image: []string{"nginx:1.16.0"}},
+			{Kind: k8s.KindDeployment, Name: "sample-metrics", Type: k8s.ImageTypeOptional, Image: []string{"prom/statsd-exporter:v0.28.0"}},
+		}, getImages(t, true))
+	})
+
+	t.Run("should not list the images of the optional components without exhaustive", func(t *testing.T) {
+		assert.Equal(t, []k8s.Image{{Kind: k8s.KindDeployment, Name: "sample", Image: []string{"nginx:1.16.0"}}}, getImages(t, false))
+	})
+}
+
+func TestImages_GetImagesWithLookupObjects(t *testing.T) {
+	getImages := func(t *testing.T, lookupObjects ...string) []string {
+		t.Helper()
+
+		imageClient := &pkg.Images{
+			Kind:          []string{k8s.KindPod},
+			ImageRegex:    pkg.ImageRegex,
+			LookupObjects: lookupObjects,
+		}
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+
+		return getImageNames(getImagesOutput(t, imageClient))
+	}
+
+	t.Run("should render the chart with the objects returned by lookup", func(t *testing.T) {
+		assert.Contains(t, getImages(t, "../example/lookup"), "registry.example.com/library/nginx:1.25.0")
+	})
+
+	t.Run("should look up the objects from the lists only in the namespace looked up", func(t *testing.T) {
+		objects := filepath.Join(t.TempDir(), "configmaps.json")
+		require.NoError(t, os.WriteFile(objects, []byte(`{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "registry-mirror", "namespace": "mirrors"}, "data": {"host": "mirror.example.com"}},
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "registry-mirror", "namespace": "default"}, "data": {"host": "list.example.com"}}
+]}`), 0o600))
+
+		images := getImages(t, objects)
+		assert.Contains(t, images, "list.example.com/library/nginx:1.25.0")
+		assert.NotContains(t, images, "mirror.example.com/library/nginx:1.25.0")
+	})
+
+	t.Run("should not render the objects returned by lookup without them", func(t *testing.T) {
+		assert.NotContains(t, getImages(t), "registry.example.com/library/nginx:1.25.0")
+	})
+
+	t.Run("should render the chart with objects for lookup against the capabilities of the cluster with validate", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Content-Type", "application/json")
+
+			switch request.URL.Path {
+			case "/version":
+				_, _ = writer.Write([]byte(`{"major": "1", "minor": "30", "gitVersion": "v1.30.2"}`))
+			case "/api":
+				_, _ = writer.Write([]byte(`{"kind": "APIVersions", "versions": ["v1"]}`))
+			case "/apis":
+				_, _ = writer.Write([]byte(`{"kind": "APIGroupList", "apiVersion": "v1", "groups": []}`))
+			case "/api/v1":
+				_, _ = writer.Write([]byte(`{"kind": "APIResourceList", "groupVersion": "v1", "resources": [{"name": "pods", "namespaced": true, "kind": "Pod", "verbs": ["get"]}]}`))
+			case "/openapi/v3":
+				_, _ = writer.Write([]byte(`{"paths": {"api/v1": {"serverRelativeURL": "/openapi/v3/api/v1"}}}`))
+			case "/openapi/v3/api/v1":
+				_, _ = writer.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Kubernetes", "version": "v1.30.2"}, "paths": {"/api/v1/namespaces/{namespace}/pods/{name}": {
+  "patch": {"x-kubernetes-group-version-kind": {"group": "", "version": "v1", "kind": "Pod"}, "parameters": [{"name": "fieldValidation", "in": "query"}]}}}}`))
+			default:
+				http.NotFound(writer, request)
+			}
+		}))
+		defer server.Close()
+
+		kubeConfig := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(kubeConfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+  - name: test
+    cluster:
+      server: %s
+contexts:
+  - name: test
+    context:
+      cluster: test
+current-context: test
+`, server.URL)), 0o600))
+		t.Setenv("KUBECONFIG", kubeConfig)
+
+		chartPath := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(chartPath, "templates"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(chartPath, "Chart.yaml"), []byte("apiVersion: v2\nname: lookup\nversion: 0.1.0\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(chartPath, "templates", "pod.yaml"), []byte(`{{- $mirror := lookup "v1" "ConfigMap" .Release.Namespace "registry-mirror" }}
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+    - name: app
+      image: {{ $mirror.data.host }}/library/nginx:{{ if semverCompare ">=1.29-0" .Capabilities.KubeVersion.Version }}1.27.0{{ else }}1.25.0{{ end }}
+`), 0o600))
+
+		imageClient := &pkg.Images{
+			Kind:          []string{k8s.KindPod},
+			ImageRegex:    pkg.ImageRegex,
+			LookupObjects: []string{"../example/lookup"},
+			Validate:      true,
+		}
+		imageClient.SetRelease("sample")
+		imageClient.SetChart(chartPath)
+
+		assert.Equal(t, []string{"registry.example.com/library/nginx:1.27.0"}, getImageNames(getImagesOutput(t, imageClient)))
+	})
+
+	t.Run("should fail to render the chart with objects for lookup with validate when the cluster is unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+		t.Setenv("HELM_KUBEAPISERVER", server.URL)
+
+		imageClient := &pkg.Images{
+			Kind:          []string{k8s.KindPod},
+			ImageRegex:    pkg.ImageRegex,
+			LookupObjects: []string{"../example/lookup"},
+			Validate:      true,
+		}
+		imageClient.SetLogger("info")
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+
+		assert.ErrorContains(t, imageClient.GetImages(context.Background()), "Kubernetes cluster unreachable")
+	})
+
+	t.Run("should fail to render the chart with objects for lookup by the helm binary", func(t *testing.T) {
+		imageClient := &pkg.Images{
+			Kind:          []string{k8s.KindPod},
+			ImageRegex:    pkg.ImageRegex,
+			RenderMode:    pkg.RenderModeExec,
+			LookupObjects: []string{"../example/lookup"},
+		}
+		imageClient.SetLogger("info")
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+
+		assert.ErrorContains(t, imageClient.GetImages(context.Background()), "supported only with render mode 'sdk'")
+	})
+}
+
+func TestImages_GetImagesBestEffort(t *testing.T) {
+	newImageClient := func(bestEffort bool, retries int) *pkg.Images {
+		imageClient := &pkg.Images{
+			Kind:              k8s.SupportedKinds(),
+			ImageRegex:        pkg.ImageRegex,
+			OutputFormat:      "json",
+			NoColor:           true,
+			BestEffort:        bestEffort,
+			BestEffortRetries: retries,
+		}
+		imageClient.SetLogger("info")
+		imageClient.SetOutputFormats()
+		imageClient.SetRelease("required")
+		imageClient.SetChart("../example/chart/required")
+		imageClient.SetRenderer()
+
+		return imageClient
+	}
+
+	t.Run("should render the chart with placeholders for the required values and report them", func(t *testing.T) {
+		output := getImagesOutput(t, newImageClient(true, pkg.DefaultBestEffortRetries))
+		assert.Equal(t, []k8s.Image{{
+			Kind:  k8s.KindDeployment,
+			Name:  "required",
+			Image: []string{fmt.Sprintf("%[1]s/app:%[1]s", pkg.PlaceholderValue)},
+		}}, output.ImagesFromRelease)
+		assert.Equal(t, []string{"license.key", "image.registry", "image.tag"}, output.Placeholders)
+	})
+
+	t.Run("should fail once the chart is rendered again as many times as the retries", func(t *testing.T) {
+		err := newImageClient(true, 1).GetImages(context.Background())
+		assert.ErrorContains(t, err, "rendering chart with placeholders for 1 required value(s) errored")
+	})
+
+	t.Run("should fail to render the chart requiring values without best effort", func(t *testing.T) {
+		err := newImageClient(false, 0).GetImages(context.Background())
+		assert.ErrorContains(t, err, "A valid .Values.license.key is required")
+	})
+}
+
+func TestImages_GetImagesWithTemplateOptions(t *testing.T) {
+	getImages := func(t *testing.T, imageClient *pkg.Images) []string {
+		t.Helper()
+
+		imageClient.Kind = []string{k8s.KindPod, k8s.KindDeployment}
+		imageClient.ImageRegex = pkg.ImageRegex
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+
+		return getImageNames(getImagesOutput(t, imageClient))
+	}
+
+	t.Run("should list the images of the hooks by default", func(t *testing.T) {
+		assert.Contains(t, getImages(t, &pkg.Images{}), "busybox")
+	})
+
+	t.Run("should skip the images of the hooks with no hooks", func(t *testing.T) {
+		assert.NotContains(t, getImages(t, &pkg.Images{NoHooks: true}), "busybox")
+	})
+
+	t.Run("should render the chart with the values set as JSON", func(t *testing.T) {
+		images := getImages(t, &pkg.Images{JSONValues: []string{`image={"repository": "ghcr.io/nginx", "tag": "1.27.0"}`}})
+		assert.Contains(t, images, "ghcr.io/nginx:1.27.0")
+	})
+
+	t.Run("should render the chart with the values set literally", func(t *testing.T) {
+		images := getImages(t, &pkg.Images{LiteralValues: []string{"image.repository=registry.example.com/nginx"}})
+		assert.Contains(t, images, "registry.example.com/nginx:1.16.0")
+	})
+
+	t.Run("should render the chart for the namespace set", func(t *testing.T) {
+		objects := filepath.Join(t.TempDir(), "configmap.yaml")
+		require.NoError(t, os.WriteFile(objects, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: registry-mirror
+  namespace: mirrors
+data:
+  host: mirror.example.com
+`), 0o600))
+
+		assert.NotContains(t, getImages(t, &pkg.Images{LookupObjects: []string{objects}}), "mirror.example.com/library/nginx:1.25.0")
+		assert.Contains(t, getImages(t, &pkg.Images{LookupObjects: []string{objects}, Namespace: "mirrors"}), "mirror.example.com/library/nginx:1.25.0")
+	})
+
+	t.Run("should render the chart for the namespace handed over by helm, as helm consumes -n/--namespace of helm images", func(t *testing.T) {
+		objects := filepath.Join(t.TempDir(), "configmap.yaml")
+		require.NoError(t, os.WriteFile(objects, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: registry-mirror
+  namespace: mirrors
+data:
+  host: mirror.example.com
+`), 0o600))
+		t.Setenv("HELM_NAMESPACE", "mirrors")
+
+		assert.Contains(t, getImages(t, &pkg.Images{LookupObjects: []string{objects}}), "mirror.example.com/library/nginx:1.25.0")
+	})
+
+	t.Run("should pass the namespace handed over by helm to the helm binary", func(t *testing.T) {
+		argsFile := filepath.Join(t.TempDir(), "args")
+		helmBin := filepath.Join(t.TempDir(), "helm")
+		require.NoError(t, os.WriteFile(helmBin, []byte(fmt.Sprintf("#!/bin/sh\nprintf '%%s\\n' \"$@\" > %s\n", argsFile)), 0o700)) //nolint:gosec
+		t.Setenv("HELM_BIN", helmBin)
+		t.Setenv("HELM_NAMESPACE", "mirrors")
+
+		imageClient := &pkg.Images{Kind: []string{k8s.KindDeployment}, ImageRegex: pkg.ImageRegex, RenderMode: pkg.RenderModeExec}
+		imageClient.SetLogger("info")
+		imageClient.SetRelease("sample")
+		imageClient.SetChart("../example/chart/sample")
+		require.NoError(t, imageClient.GetImages(context.Background()))
+
+		args, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(args), "--namespace\nmirrors\n")
+	})
+}
+
+func TestImages_GetImagesWithCache(t *testing.T) {
+	chartPath := filepath.Join(t.TempDir(), "sample")
+	require.NoError(t, os.CopyFS(chartPath, os.DirFS("../example/chart/sample")))
+
+	cacheDir := t.TempDir()
+
+	getImages := func(t *testing.T, noCache bool, values ...string) []string {
+		t.Helper()
+
+		imageClient := &pkg.Images{
+			Kind:       []string{k8s.KindDeployment},
+			ImageRegex: pkg.ImageRegex,
+			Values:     values,
+			CacheDir:   cacheDir,
+			NoCache:    noCache,
+		}
+		imageClient.SetRelease("sample")
+		imageClient.SetChart(chartPath)
+
+		return getImageNames(getImagesOutput(t, imageClient))
+	}
+
+	getCacheFiles := func(t *testing.T) []string {
+		t.Helper()
+
+		files, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+		require.NoError(t, err)
+
+		return files
+	}
+
+	t.Run("should cache the render of the chart", func(t *testing.T) {
+		assert.Equal(t, []string{"nginx:1.16.0"}, getImages(t, false))
+		assert.Len(t, getCacheFiles(t), 1)
+	})
+
+	t.Run("should list the images from the cached render", func(t *testing.T) {
+		cacheFile := getCacheFiles(t)[0]
+		require.NoError(t, os.WriteFile(cacheFile, []byte(`{"manifests": "---\n# Source: sample/templates/deployment.yaml\n`+
+			`apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: cached\nspec:\n  template:\n    spec:\n      containers:\n`+
+			`        - name: cached\n          image: cached/nginx:1.16.0\n"}`), 0o600))
+
+		assert.Equal(t, []string{"cached/nginx:1.16.0"}, getImages(t, false))
+		assert.Len(t, getCacheFiles(t), 1)
+	})
+
+	t.Run("should not read from the cache with no cache", func(t *testing.T) {
+		assert.Equal(t, []string{"nginx:1.16.0"}, getImages(t, true))
+		assert.Len(t, getCacheFiles(t), 1)
+	})
+
+	t.Run("should render the chart again when the values change", func(t *testing.T) {
+		assert.Equal(t, []string{"nginx:1.17.0"}, getImages(t, false, "image.tag=1.17.0"))
+		assert.Len(t, getCacheFiles(t), 2)
+	})
+
+	t.Run("should render the chart again when the chart changes", func(t *testing.T) {
+		valuesFile := filepath.Join(chartPath, "values.yaml")
+		content, err := os.ReadFile(valuesFile)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(valuesFile, []byte(strings.Replace(string(content), "repository: nginx", "repository: ghcr.io/nginx", 1)), 0o600))
+
+		assert.Equal(t, []string{"ghcr.io/nginx:1.16.0"}, getImages(t, false))
+		assert.Len(t, getCacheFiles(t), 3)
+	})
+
+	t.Run("should not cache the render of the chart with the dependencies updated", func(t *testing.T) {
+		imageClient := &pkg.Images{
+			Kind:             []string{k8s.KindDeployment},
+			ImageRegex:       pkg.ImageRegex,
+			CacheDir:         cacheDir,
+			DependencyUpdate: true,
+		}
+		imageClient.SetRelease("sample")
+		imageClient.SetChart(chartPath)
+
+		assert.Equal(t, []string{"ghcr.io/nginx:1.16.0"}, getImageNames(getImagesOutput(t, imageClient)))
+		assert.Len(t, getCacheFiles(t), 3)
+	})
+
+	t.Run("should pull the chart pinned to a version only when not cached already", func(t *testing.T) {
+		helmHome := t.TempDir()
+		t.Setenv("HELM_CACHE_HOME", filepath.Join(helmHome, "cache"))
+		t.Setenv("HELM_CONFIG_HOME", filepath.Join(helmHome, "config"))
+		t.Setenv("HELM_DATA_HOME", filepath.Join(helmHome, "data"))
+
+		var pulls atomic.Int32
+
+		registry := newOCIRegistry(t, "../example/chart/sample", "admin", "secret")
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if strings.Contains(request.URL.Path, "/manifests/") {
+				pulls.Add(1)
+			}
+
+			registry.ServeHTTP(writer, request)
+		}))
+		defer server.Close()
+
+		getPinnedImages := func(t *testing.T, version string) []string {
+			t.Helper()
+
+			imageClient := &pkg.Images{
+				Kind:       []string{k8s.KindDeployment},
+				ImageRegex: pkg.ImageRegex,
+				Version:    version,
+				PlainHTTP:  true,
+				Username:   "admin",
+				Password:   "secret",
+				CacheDir:   cacheDir,
+			}
+			imageClient.SetRelease("sample")
+			imageClient.SetChart(fmt.Sprintf("oci://%s/charts/sample", strings.TrimPrefix(server.URL, "http://")))
+
+			return getImageNames(getImagesOutput(t, imageClient))
+		}
+
+		assert.Equal(t, []string{"nginx:1.16.0"}, getPinnedImages(t, "0.1.0"))
+		pulled := pulls.Load()
+		assert.Positive(t, pulled)
+
+		assert.Equal(t, []string{"nginx:1.16.0"}, getPinnedImages(t, "0.1.0"))
+		assert.Equal(t, pulled, pulls.Load())
+	})
+}
+
+func TestImages_GetImagesFromChartsDirRecursively(t *testing.T) {
+	chartsDir := t.TempDir()
+
+	for _, chartPath := range []string{"top", "team-a/app", "team-b/web", "deprecated/old"} {
+		require.NoError(t, os.CopyFS(filepath.Join(chartsDir, chartPath), os.DirFS("../example/chart/sample")))
+	}
+
+	vendoredChart := filepath.Join(chartsDir, "team-a", "app", "charts", "vendored")
+	require.NoError(t, os.MkdirAll(vendoredChart, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(vendoredChart, "Chart.yaml"), []byte("apiVersion: v2\nname: vendored\nversion: 0.1.0\n"), 0o600))
+
+	sampleChart, err := loader.Load("../example/chart/sample")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(chartsDir, "packaged"), 0o755))
+	_, err = chartutil.Save(sampleChart, filepath.Join(chartsDir, "packaged"))
+	require.NoError(t, err)
+
+	// Packaged files shipped by a chart are not charts of the charts directory.
+	require.NoError(t, os.MkdirAll(filepath.Join(chartsDir, "team-b", "web", "files"), 0o755))
+	_, err = chartutil.Save(sampleChart, filepath.Join(chartsDir, "team-b", "web", "files"))
+	require.NoError(t, err)
+
+	getCharts := func(t *testing.T, depth int, include, exclude []string) []string {
+		t.Helper()
+
+		out := captureStdout(t, func() error {
+			imageClient := &pkg.Images{
+				Kind:             []string{k8s.KindDeployment},
+				ImageRegex:       pkg.ImageRegex,
+				OutputFormat:     "json",
+				ChartsDirDepth:   depth,
+				ChartsDirInclude: include,
+				ChartsDirExclude: exclude,
+				NoColor:          true,
+			}
+			imageClient.SetLogger("info")
+			imageClient.SetOutputFormats()
+			imageClient.SetChartsDir(chartsDir)
+			imageClient.SetRenderer()
+
+			return imageClient.GetImagesFromChartsDir(context.Background())
+		})
+
+		var images []k8s.Images
+		require.NoError(t, json.Unmarshal(out, &images))
+
+		charts := make([]string, 0, len(images))
+		for _, chartImages := range images {
+			charts = append(charts, chartImages.NameSpace)
+		}
+
+		return charts
+	}
+
+	t.Run("should discover only the charts directly under the charts directory by default", func(t *testing.T) {
+		assert.Equal(t, []string{"top"}, getCharts(t, 0, nil, nil))
+	})
+
+	t.Run("should discover the charts up to the depth set, skipping the vendored subcharts", func(t *testing.T) {
+		expected := []string{"deprecated/old", "packaged/sample-0.1.0", "team-a/app", "team-b/web", "top"}
+
+		assert.Equal(t, expected, getCharts(t, 2, nil, nil))
+		assert.Equal(t, expected, getCharts(t, -1, nil, nil))
+	})
+
+	t.Run("should skip the charts and directories excluded", func(t *testing.T) {
+		assert.Equal(t, []string{"team-a/app", "team-b/web", "top"}, getCharts(t, -1, nil, []string{"deprecated/", "*.tgz"}))
+	})
+
+	t.Run("should discover only the charts included", func(t *testing.T) {
+		assert.Equal(t, []string{"team-a/app", "team-b/web"}, getCharts(t, -1, []string{"team-*/"}, nil))
+		assert.Equal(t, []string{"team-a/app"}, getCharts(t, -1, []string{"team-*/"}, []string{"web"}))
+	})
+}
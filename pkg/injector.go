@@ -0,0 +1,241 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ghodss/yaml"
+	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"github.com/nikhilsbhat/helm-images/pkg/k8s"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	injectorImage     = "image"
+	injectorInitImage = "initImage"
+	kindNamespace     = "Namespace"
+)
+
+// Injectors holds the configurations of the admission webhooks those inject containers into pods,
+// ex: Istio, Linkerd, Vault Agent or Dapr, used for predicting the images that are not part of the rendered manifests.
+type Injectors struct {
+	Injectors []Injector `json:"injectors,omitempty" yaml:"injectors,omitempty"`
+}
+
+// Injector holds the configuration of an injector.
+// A pod is injected when it is part of Namespaces, or of a namespace in the manifests matching NamespaceLabels
+// or when its labels/annotations match PodSelector and it is not opted out by OptOut.
+// Overrides maps the pod annotations that override the injected images to the image it overrides, either 'image' or 'initImage'.
+type Injector struct {
+	Name            string            `json:"name,omitempty"             yaml:"name,omitempty"`
+	Image           string            `json:"image,omitempty"            yaml:"image,omitempty"`
+	InitImage       string            `json:"initImage,omitempty"        yaml:"initImage,omitempty"`
+	Namespaces      []string          `json:"namespaces,omitempty"       yaml:"namespaces,omitempty"`
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"  yaml:"namespaceLabels,omitempty"`
+	PodSelector     PodMetadata       `json:"podSelector,omitempty"      yaml:"podSelector,omitempty"`
+	OptOut          PodMetadata       `json:"optOut,omitempty"           yaml:"optOut,omitempty"`
+	Overrides       map[string]string `json:"overrides,omitempty"        yaml:"overrides,omitempty"`
+}
+
+// PodMetadata holds labels and annotations of a pod, any of which when matched is considered as a match.
+type PodMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"      yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+type podWorkload struct {
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		Template    *coreV1.PodTemplateSpec `json:"template,omitempty"`
+		JobTemplate *struct {
+			Spec struct {
+				Template coreV1.PodTemplateSpec `json:"template,omitempty"`
+			} `json:"spec,omitempty"`
+		} `json:"jobTemplate,omitempty"`
+	} `json:"spec,omitempty"`
+}
+
+// SetInjectors loads the injectors from the file set under InjectorsConfig.
+func (image *Images) SetInjectors() error {
+	if len(image.InjectorsConfig) == 0 {
+		return nil
+	}
+
+	image.log.Debugf("loading injector configurations from '%s'", image.InjectorsConfig)
+
+	content, err := os.ReadFile(image.InjectorsConfig)
+	if err != nil {
+		return err
+	}
+
+	var injectors Injectors
+	if err = yaml.Unmarshal(content, &injectors); err != nil {
+		return &imgErrors.ImageError{Message: fmt.Sprintf("parsing injector config '%s' errored with '%v'", image.InjectorsConfig, err)}
+	}
+
+	for _, injector := range injectors.Injectors {
+		for annotation, overrides := range injector.Overrides {
+			if overrides != injectorImage && overrides != injectorInitImage {
+				return &imgErrors.ImageError{
+					Message: fmt.Sprintf("override '%s' of injector '%s' should either be '%s' or '%s'",
+						annotation, injector.Name, injectorImage, injectorInitImage),
+				}
+			}
+		}
+	}
+
+	image.injectors = injectors.Injectors
+
+	return nil
+}
+
+// injectionContext holds what the injectors are matched against, other than the workload itself. The namespace is considered
+// as the namespace of the workloads those do not set one, and namespaceLabels are the labels of the namespaces part of the manifests.
+type injectionContext struct {
+	namespace       string
+	namespaceLabels map[string]map[string]string
+}
+
+// getInjectionContext collects the labels of the namespaces part of the manifests, to be matched against NamespaceLabels of injectors.
+func (image *Images) getInjectionContext(kubeKindTemplates []string, namespace string) injectionContext {
+	injection := injectionContext{namespace: namespace, namespaceLabels: make(map[string]map[string]string)}
+
+	if len(image.injectors) == 0 {
+		return injection
+	}
+
+	for _, kubeKindTemplate := range kubeKindTemplates {
+		currentKind, err := k8s.NewKind().Get(kubeKindTemplate, image.log)
+		if err != nil || currentKind != kindNamespace {
+			continue
+		}
+
+		var namespace coreV1.Namespace
+		if err = yaml.Unmarshal([]byte(kubeKindTemplate), &namespace); err != nil {
+			image.log.Debugf("reading labels of the namespace errored with '%v'", err)
+
+			continue
+		}
+
+		injection.namespaceLabels[namespace.Name] = namespace.Labels
+	}
+
+	return injection
+}
+
+// getInjectedImages predicts the images injected into the pods of the workload, by the injectors configured.
+func (image *Images) getInjectedImages(currentKind, kubeKindTemplate string, injection injectionContext) (*k8s.Image, error) {
+	if len(image.injectors) == 0 {
+		return nil, nil
+	}
+
+	switch currentKind {
+	case k8s.KindDeployment, k8s.KindStatefulSet, k8s.KindDaemonSet, k8s.KindReplicaSet,
+		k8s.KindJob, k8s.KindCronJob, k8s.KindPod:
+	default:
+		return nil, nil
+	}
+
+	var workload podWorkload
+	if err := yaml.Unmarshal([]byte(kubeKindTemplate), &workload); err != nil {
+		return nil, err
+	}
+
+	podMeta := workload.ObjectMeta
+
+	switch {
+	case workload.Spec.JobTemplate != nil:
+		podMeta = workload.Spec.JobTemplate.Spec.Template.ObjectMeta
+	case workload.Spec.Template != nil:
+		podMeta = workload.Spec.Template.ObjectMeta
+	}
+
+	namespace := workload.Namespace
+	if len(namespace) == 0 {
+		namespace = injection.namespace
+	}
+
+	injected := &k8s.Image{
+		Kind:  currentKind,
+		Name:  workload.Name,
+		Type:  k8s.ImageTypeInjected,
+		Image: make([]string, 0),
+	}
+
+	for _, injector := range image.injectors {
+		if !injector.injects(namespace, injection.namespaceLabels[namespace], podMeta) {
+			continue
+		}
+
+		image.log.Debugf("'%s' of kind '%s' would be injected by '%s'", workload.Name, currentKind, injector.Name)
+
+		injected.Image = append(injected.Image, injector.getImages(podMeta.Annotations)...)
+	}
+
+	if len(injected.Image) == 0 {
+		return nil, nil
+	}
+
+	return injected, nil
+}
+
+func (injector Injector) injects(namespace string, namespaceLabels map[string]string, podMeta metaV1.ObjectMeta) bool {
+	if injector.OptOut.matches(podMeta) {
+		return false
+	}
+
+	if injector.PodSelector.matches(podMeta) {
+		return true
+	}
+
+	if Contains(injector.Namespaces, namespace) {
+		return true
+	}
+
+	return len(injector.NamespaceLabels) != 0 && isSubset(injector.NamespaceLabels, namespaceLabels)
+}
+
+func (injector Injector) getImages(annotations map[string]string) []string {
+	images := map[string]string{injectorImage: injector.Image, injectorInitImage: injector.InitImage}
+
+	for annotation, overrides := range injector.Overrides {
+		if value, found := annotations[annotation]; found && len(value) != 0 {
+			images[overrides] = value
+		}
+	}
+
+	injectedImages := make([]string, 0)
+
+	for _, img := range []string{images[injectorImage], images[injectorInitImage]} {
+		if len(img) != 0 {
+			injectedImages = append(injectedImages, img)
+		}
+	}
+
+	return injectedImages
+}
+
+func (metadata PodMetadata) matches(podMeta metaV1.ObjectMeta) bool {
+	return hasAny(metadata.Labels, podMeta.Labels) || hasAny(metadata.Annotations, podMeta.Annotations)
+}
+
+func hasAny(expected, actual map[string]string) bool {
+	for key, value := range expected {
+		if actualValue, found := actual[key]; found && actualValue == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSubset(expected, actual map[string]string) bool {
+	for key, value := range expected {
+		if actualValue, found := actual[key]; !found || actualValue != value {
+			return false
+		}
+	}
+
+	return true
+}
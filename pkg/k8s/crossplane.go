@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	crossplaneV1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	crossplaneAlphaV1 "github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	crossplaneBetaV1 "github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/ghodss/yaml"
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+)
+
+const (
+	KindCrossPlaneDeploymentRuntimeConfig = "DeploymentRuntimeConfig"
+	KindCrossPlaneControllerConfig        = "ControllerConfig"
+	// crossplane defaults runtimeConfigRef of the packages to DeploymentRuntimeConfig named default.
+	crossPlaneDefaultRuntimeConfig = "default"
+)
+
+type (
+	CrossPlaneDeploymentRuntimeConfig crossplaneBetaV1.DeploymentRuntimeConfig
+	CrossPlaneControllerConfig        crossplaneAlphaV1.ControllerConfig
+)
+
+// Get identifies images from CrossPlaneDeploymentRuntimeConfig.
+func (dep *CrossPlaneDeploymentRuntimeConfig) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	runtimeContainers := make([]coreV1.Container, 0)
+
+	if template := dep.Spec.DeploymentTemplate; template != nil && template.Spec != nil {
+		runtimeContainers = append(runtimeContainers, template.Spec.Template.Spec.Containers...)
+		runtimeContainers = append(runtimeContainers, template.Spec.Template.Spec.InitContainers...)
+	}
+
+	images := &Image{
+		Kind:  KindCrossPlaneDeploymentRuntimeConfig,
+		Name:  dep.Name,
+		Image: containers{runtimeContainers}.getDefinedImages(),
+	}
+
+	return images, nil
+}
+
+// Get identifies images from CrossPlaneControllerConfig.
+func (dep *CrossPlaneControllerConfig) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:  KindCrossPlaneControllerConfig,
+		Name:  dep.Name,
+		Image: make([]string, 0),
+	}
+
+	if dep.Spec.Image != nil && len(*dep.Spec.Image) != 0 {
+		images.Image = append(images.Image, *dep.Spec.Image)
+	}
+
+	return images, nil
+}
+
+// NewCrossPlaneDeploymentRuntimeConfig returns new instance of CrossPlaneDeploymentRuntimeConfig.
+func NewCrossPlaneDeploymentRuntimeConfig() ImagesInterface {
+	return &CrossPlaneDeploymentRuntimeConfig{}
+}
+
+// NewCrossPlaneControllerConfig returns new instance of CrossPlaneControllerConfig.
+func NewCrossPlaneControllerConfig() ImagesInterface {
+	return &CrossPlaneControllerConfig{}
+}
+
+// ResolveRuntimeConfigs adds the images of DeploymentRuntimeConfig and ControllerConfig to the Crossplane
+// packages referring them, when defined in the same set of manifests.
+// The package image is still reported as is, since crossplane pulls it to install the package irrespective of the runtime image.
+func ResolveRuntimeConfigs(images []*Image, log *logrus.Logger) {
+	runtimeConfigs := make(map[string][]string)
+
+	for _, img := range images {
+		if img.Kind == KindCrossPlaneDeploymentRuntimeConfig || img.Kind == KindCrossPlaneControllerConfig {
+			runtimeConfigs[img.Kind+"/"+img.Name] = img.Image
+		}
+	}
+
+	for _, img := range images {
+		for _, reference := range img.RuntimeConfigRefs {
+			runtimeImages, found := runtimeConfigs[reference]
+			if !found {
+				log.Debugf("runtime config '%s' referred by '%s' of kind '%s' is not defined in the manifests, hence cannot be linked",
+					reference, img.Name, img.Kind)
+
+				continue
+			}
+
+			img.Image = append(img.Image, runtimeImages...)
+		}
+	}
+}
+
+func getRuntimeConfigRefs(runtimeSpec crossplaneV1.PackageRuntimeSpec) []string {
+	references := make([]string, 0)
+
+	if runtimeSpec.ControllerConfigReference != nil {
+		references = append(references, KindCrossPlaneControllerConfig+"/"+runtimeSpec.ControllerConfigReference.Name)
+	}
+
+	runtimeConfig := crossPlaneDefaultRuntimeConfig
+	if runtimeSpec.RuntimeConfigReference != nil && len(runtimeSpec.RuntimeConfigReference.Name) != 0 {
+		runtimeConfig = runtimeSpec.RuntimeConfigReference.Name
+	}
+
+	return append(references, KindCrossPlaneDeploymentRuntimeConfig+"/"+runtimeConfig)
+}
@@ -0,0 +1,312 @@
+package k8s
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/ghodss/yaml"
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	KindSparkApplication          = "SparkApplication"
+	KindScheduledSparkApplication = "ScheduledSparkApplication"
+	KindRayCluster                = "RayCluster"
+	KindRayJob                    = "RayJob"
+	KindRayService                = "RayService"
+	KindTFJob                     = "TFJob"
+	KindPyTorchJob                = "PyTorchJob"
+)
+
+// Only the fields carrying images are declared, importing the upstream modules would pull in their entire dependency tree.
+type (
+	SparkApplication struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              sparkApplicationSpec `json:"spec,omitempty"`
+	}
+	ScheduledSparkApplication struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			Template sparkApplicationSpec `json:"template,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	RayCluster struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              rayClusterSpec `json:"spec,omitempty"`
+	}
+	RayJob struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			RayClusterSpec       *rayClusterSpec         `json:"rayClusterSpec,omitempty"`
+			SubmitterPodTemplate *coreV1.PodTemplateSpec `json:"submitterPodTemplate,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	RayService struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			RayClusterSpec rayClusterSpec `json:"rayClusterConfig,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	TFJob struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			ReplicaSpecs map[string]*replicaSpec `json:"tfReplicaSpecs,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	PyTorchJob struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			ReplicaSpecs map[string]*replicaSpec `json:"pytorchReplicaSpecs,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	sparkApplicationSpec struct {
+		Image              *string      `json:"image,omitempty"`
+		InitContainerImage *string      `json:"initContainerImage,omitempty"`
+		Driver             sparkPodSpec `json:"driver,omitempty"`
+		Executor           sparkPodSpec `json:"executor,omitempty"`
+	}
+	sparkPodSpec struct {
+		Image          *string            `json:"image,omitempty"`
+		InitContainers []coreV1.Container `json:"initContainers,omitempty"`
+		Sidecars       []coreV1.Container `json:"sidecars,omitempty"`
+	}
+	rayClusterSpec struct {
+		HeadGroupSpec    rayGroupSpec   `json:"headGroupSpec,omitempty"`
+		WorkerGroupSpecs []rayGroupSpec `json:"workerGroupSpecs,omitempty"`
+	}
+	rayGroupSpec struct {
+		Template coreV1.PodTemplateSpec `json:"template,omitempty"`
+	}
+	replicaSpec struct {
+		Template coreV1.PodTemplateSpec `json:"template,omitempty"`
+	}
+)
+
+// Get identifies images from SparkApplication.
+func (dep *SparkApplication) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:  KindSparkApplication,
+		Name:  dep.Name,
+		Image: dep.Spec.getImages(),
+	}
+
+	return images, nil
+}
+
+// Get identifies images from ScheduledSparkApplication.
+func (dep *ScheduledSparkApplication) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:  KindScheduledSparkApplication,
+		Name:  dep.Name,
+		Image: dep.Spec.Template.getImages(),
+	}
+
+	return images, nil
+}
+
+// Get identifies images from RayCluster.
+func (dep *RayCluster) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	depContainers := containers{dep.Spec.getContainers()}
+
+	images := &Image{
+		Kind:  KindRayCluster,
+		Name:  dep.Name,
+		Image: depContainers.getDefinedImages(),
+	}
+
+	images.Image = append(images.Image, depContainers.getImagesFromArgs()...)
+
+	return images, nil
+}
+
+// Get identifies images from RayJob.
+func (dep *RayJob) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	rayContainers := make([]coreV1.Container, 0)
+
+	if dep.Spec.RayClusterSpec != nil {
+		rayContainers = append(rayContainers, dep.Spec.RayClusterSpec.getContainers()...)
+	}
+
+	if dep.Spec.SubmitterPodTemplate != nil {
+		rayContainers = append(rayContainers, dep.Spec.SubmitterPodTemplate.Spec.Containers...)
+		rayContainers = append(rayContainers, dep.Spec.SubmitterPodTemplate.Spec.InitContainers...)
+	}
+
+	depContainers := containers{rayContainers}
+
+	images := &Image{
+		Kind:  KindRayJob,
+		Name:  dep.Name,
+		Image: depContainers.getDefinedImages(),
+	}
+
+	images.Image = append(images.Image, depContainers.getImagesFromArgs()...)
+
+	return images, nil
+}
+
+// Get identifies images from RayService.
+func (dep *RayService) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	depContainers := containers{dep.Spec.RayClusterSpec.getContainers()}
+
+	images := &Image{
+		Kind:  KindRayService,
+		Name:  dep.Name,
+		Image: depContainers.getDefinedImages(),
+	}
+
+	images.Image = append(images.Image, depContainers.getImagesFromArgs()...)
+
+	return images, nil
+}
+
+// Get identifies images from TFJob.
+func (dep *TFJob) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	depContainers := containers{getReplicaSpecsContainers(dep.Spec.ReplicaSpecs)}
+
+	images := &Image{
+		Kind:  KindTFJob,
+		Name:  dep.Name,
+		Image: depContainers.getDefinedImages(),
+	}
+
+	images.Image = append(images.Image, depContainers.getImagesFromArgs()...)
+
+	return images, nil
+}
+
+// Get identifies images from PyTorchJob.
+func (dep *PyTorchJob) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	depContainers := containers{getReplicaSpecsContainers(dep.Spec.ReplicaSpecs)}
+
+	images := &Image{
+		Kind:  KindPyTorchJob,
+		Name:  dep.Name,
+		Image: depContainers.getDefinedImages(),
+	}
+
+	images.Image = append(images.Image, depContainers.getImagesFromArgs()...)
+
+	return images, nil
+}
+
+// NewSparkApplication returns new instance of SparkApplication.
+func NewSparkApplication() ImagesInterface {
+	return &SparkApplication{}
+}
+
+// NewScheduledSparkApplication returns new instance of ScheduledSparkApplication.
+func NewScheduledSparkApplication() ImagesInterface {
+	return &ScheduledSparkApplication{}
+}
+
+// NewRayCluster returns new instance of RayCluster.
+func NewRayCluster() ImagesInterface {
+	return &RayCluster{}
+}
+
+// NewRayJob returns new instance of RayJob.
+func NewRayJob() ImagesInterface {
+	return &RayJob{}
+}
+
+// NewRayService returns new instance of RayService.
+func NewRayService() ImagesInterface {
+	return &RayService{}
+}
+
+// NewTFJob returns new instance of TFJob.
+func NewTFJob() ImagesInterface {
+	return &TFJob{}
+}
+
+// NewPyTorchJob returns new instance of PyTorchJob.
+func NewPyTorchJob() ImagesInterface {
+	return &PyTorchJob{}
+}
+
+// spec.image is used for both driver and executor unless they override it, so the
+// overrides are reported in addition to the application level image.
+func (spec sparkApplicationSpec) getImages() []string {
+	images := make([]string, 0)
+
+	for _, image := range []*string{spec.Image, spec.InitContainerImage, spec.Driver.Image, spec.Executor.Image} {
+		if image != nil && len(*image) != 0 {
+			images = append(images, *image)
+		}
+	}
+
+	sparkContainers := make([]coreV1.Container, 0)
+	sparkContainers = append(sparkContainers, spec.Driver.InitContainers...)
+	sparkContainers = append(sparkContainers, spec.Driver.Sidecars...)
+	sparkContainers = append(sparkContainers, spec.Executor.InitContainers...)
+	sparkContainers = append(sparkContainers, spec.Executor.Sidecars...)
+
+	return append(images, containers{sparkContainers}.getDefinedImages()...)
+}
+
+func (spec rayClusterSpec) getContainers() []coreV1.Container {
+	rayContainers := make([]coreV1.Container, 0)
+	rayContainers = append(rayContainers, spec.HeadGroupSpec.Template.Spec.Containers...)
+	rayContainers = append(rayContainers, spec.HeadGroupSpec.Template.Spec.InitContainers...)
+
+	for _, workerGroup := range spec.WorkerGroupSpecs {
+		rayContainers = append(rayContainers, workerGroup.Template.Spec.Containers...)
+		rayContainers = append(rayContainers, workerGroup.Template.Spec.InitContainers...)
+	}
+
+	return rayContainers
+}
+
+func getReplicaSpecsContainers(replicaSpecs map[string]*replicaSpec) []coreV1.Container {
+	replicaContainers := make([]coreV1.Container, 0)
+
+	for _, replicaType := range slices.Sorted(maps.Keys(replicaSpecs)) {
+		replica := replicaSpecs[replicaType]
+		if replica == nil {
+			continue
+		}
+
+		replicaContainers = append(replicaContainers, replica.Template.Spec.Containers...)
+		replicaContainers = append(replicaContainers, replica.Template.Spec.InitContainers...)
+	}
+
+	return replicaContainers
+}
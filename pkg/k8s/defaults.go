@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"slices"
+
+	monitoringV1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	monitoringV1Alpha1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/sirupsen/logrus"
+)
+
+// ImageTypeDefaulted is the type of images not set in the manifests, but picked by the operator managing them.
+const ImageTypeDefaulted = "defaulted"
+
+// OperatorDefault holds the image an operator runs for a component of the resource it manages, when the resource does not set one.
+// Version is used only when the resource does not set the version of the component either.
+type OperatorDefault struct {
+	Kind      string `json:"kind,omitempty"      yaml:"kind,omitempty"`
+	Component string `json:"component,omitempty" yaml:"component,omitempty"`
+	BaseImage string `json:"baseImage,omitempty" yaml:"baseImage,omitempty"`
+	Version   string `json:"version,omitempty"   yaml:"version,omitempty"`
+}
+
+// ImageSpec holds the parts of the image set in the resource for a component, those are used when composing the default image.
+type ImageSpec struct {
+	BaseImage string
+	Version   string
+	Tag       string
+	SHA       string
+}
+
+// DefaultOperatorImages are the built-in defaults of the operators, see the section 'Operator defaults' of README for the versions these hold for.
+func DefaultOperatorImages() []OperatorDefault {
+	return []OperatorDefault{
+		{Kind: monitoringV1.PrometheusesKind, Component: componentPrometheus, BaseImage: "quay.io/prometheus/prometheus", Version: "v3.7.3"},
+		{Kind: monitoringV1.PrometheusesKind, Component: componentThanosSidecar, BaseImage: "quay.io/thanos/thanos", Version: "v0.39.2"},
+		{Kind: monitoringV1Alpha1.PrometheusAgentsKind, Component: componentPrometheus, BaseImage: "quay.io/prometheus/prometheus", Version: "v3.7.3"},
+		{Kind: monitoringV1.AlertmanagersKind, Component: componentAlertManager, BaseImage: "quay.io/prometheus/alertmanager", Version: "v0.29.0"},
+		{Kind: monitoringV1.ThanosRulerKind, Component: componentThanos, BaseImage: "quay.io/thanos/thanos", Version: "v0.39.2"},
+		{Kind: KindElasticsearch, Component: componentElasticsearch, BaseImage: "docker.elastic.co/elasticsearch/elasticsearch"},
+		{Kind: KindKibana, Component: componentKibana, BaseImage: "docker.elastic.co/kibana/kibana"},
+	}
+}
+
+// ResolveDefaults returns the images the operators would run for the Defaulted components of the images passed.
+// Components for which no default is known, or the version of which cannot be determined are left unresolved.
+func ResolveDefaults(images []*Image, defaults []OperatorDefault, log *logrus.Logger) []*Image {
+	resolved := make([]*Image, 0)
+
+	for _, img := range images {
+		defaulted := &Image{
+			Kind:  img.Kind,
+			Name:  img.Name,
+			Type:  ImageTypeDefaulted,
+			Image: make([]string, 0),
+		}
+
+		for _, component := range img.Defaulted {
+			image := resolveDefault(img.Kind, component, img.DefaultedSpecs[component], defaults)
+			if len(image) == 0 {
+				log.Debugf("could not resolve the default image of '%s' for '%s' of kind '%s'", component, img.Name, img.Kind)
+
+				continue
+			}
+
+			log.Debugf("resolved the default image of '%s' for '%s' of kind '%s' to '%s'", component, img.Name, img.Kind, image)
+
+			defaulted.Image = append(defaulted.Image, image)
+		}
+
+		if len(defaulted.Image) != 0 {
+			resolved = append(resolved, defaulted)
+		}
+	}
+
+	return resolved
+}
+
+func resolveDefault(kind, component string, spec ImageSpec, defaults []OperatorDefault) string {
+	for _, operatorDefault := range defaults {
+		if operatorDefault.Kind != kind || operatorDefault.Component != component {
+			continue
+		}
+
+		baseImage := spec.BaseImage
+		if len(baseImage) == 0 {
+			baseImage = operatorDefault.BaseImage
+		}
+
+		version := spec.Version
+		if len(version) == 0 {
+			version = operatorDefault.Version
+		}
+
+		return buildImagePath("", baseImage, version, spec.Tag, spec.SHA)
+	}
+
+	return ""
+}
+
+// setDefaultedSpec records the spec of the component only when it is Defaulted, as otherwise the image is already known.
+func (img *Image) setDefaultedSpec(component string, spec ImageSpec) {
+	if !slices.Contains(img.Defaulted, component) {
+		return
+	}
+
+	if img.DefaultedSpecs == nil {
+		img.DefaultedSpecs = make(map[string]ImageSpec)
+	}
+
+	img.DefaultedSpecs[component] = spec
+}
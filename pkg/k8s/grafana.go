@@ -0,0 +1,73 @@
+package k8s
+
+import (
+	"github.com/ghodss/yaml"
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const grafanaV1Alpha1APIVersion = "integreatly.org/v1alpha1"
+
+// GrafanaV1Alpha1 mirrors the fields of Grafana from the legacy grafana-operator (v4 and older) that carry images.
+type GrafanaV1Alpha1 struct {
+	metaV1.TypeMeta   `json:",inline"`
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		BaseImage      string             `json:"baseImage,omitempty"`
+		InitImage      string             `json:"initImage,omitempty"`
+		Containers     []coreV1.Container `json:"containers,omitempty"`
+		InitContainers []coreV1.Container `json:"initContainers,omitempty"`
+		Deployment     *struct {
+			Spec struct {
+				Template *struct {
+					Spec *coreV1.PodSpec `json:"spec,omitempty"`
+				} `json:"template,omitempty"`
+			} `json:"spec,omitempty"`
+		} `json:"deployment,omitempty"`
+	} `json:"spec,omitempty"`
+}
+
+// Get identifies images from Grafana of apiVersion integreatly.org/v1alpha1.
+func (dep *GrafanaV1Alpha1) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	grafanaContainers := make([]coreV1.Container, 0)
+	grafanaContainers = append(grafanaContainers, dep.Spec.Containers...)
+	grafanaContainers = append(grafanaContainers, dep.Spec.InitContainers...)
+
+	if deployment := dep.Spec.Deployment; deployment != nil && deployment.Spec.Template != nil && deployment.Spec.Template.Spec != nil {
+		grafanaContainers = append(grafanaContainers, deployment.Spec.Template.Spec.Containers...)
+		grafanaContainers = append(grafanaContainers, deployment.Spec.Template.Spec.InitContainers...)
+	}
+
+	images := &Image{
+		Kind:  KindGrafana,
+		Name:  dep.Name,
+		Image: containers{grafanaContainers}.getDefinedImages(),
+	}
+
+	for _, image := range []string{dep.Spec.BaseImage, dep.Spec.InitImage} {
+		if len(image) != 0 {
+			images.Image = append(images.Image, image)
+		}
+	}
+
+	return images, nil
+}
+
+// NewGrafanaV1Alpha1 returns new instance of GrafanaV1Alpha1.
+func NewGrafanaV1Alpha1() ImagesInterface {
+	return &GrafanaV1Alpha1{}
+}
+
+// NewGrafanaForAPIVersion returns the instance of Grafana that understands the apiVersion passed.
+func NewGrafanaForAPIVersion(apiVersion string) ImagesInterface {
+	if apiVersion == grafanaV1Alpha1APIVersion {
+		return NewGrafanaV1Alpha1()
+	}
+
+	return NewGrafana()
+}
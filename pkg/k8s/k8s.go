@@ -14,11 +14,13 @@ import (
 	"github.com/nikhilsbhat/common/content"
 	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
 	monitoringV1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	monitoringV1Alpha1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1alpha1"
 	"github.com/sirupsen/logrus"
 	"github.com/thoas/go-funk"
 	appsV1 "k8s.io/api/apps/v1"
 	batchV1 "k8s.io/api/batch/v1"
 	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
 )
 
 const (
@@ -37,6 +39,15 @@ const (
 	KindCrossPlaneConfiguration = "Configuration"
 	KindCrossPlaneFunction      = "Function"
 	kubeKind                    = "kind"
+	kubeAPIVersion              = "apiVersion"
+	componentPrometheus         = "prometheus"
+	componentAlertManager       = "alertmanager"
+	componentThanos             = "thanos"
+	componentThanosSidecar      = "thanos-sidecar"
+	// ImageTypeInjected is the type of images predicted to be injected into pods by admission webhooks.
+	ImageTypeInjected = "injected"
+	// ImageTypeOptional is the type of images those appear only when the optional components of the chart are enabled, with --exhaustive.
+	ImageTypeOptional = "optional"
 )
 
 var imagesFlags = []string{
@@ -55,6 +66,7 @@ type (
 	Pod          coreV1.Pod
 	Kind         map[string]any
 	Name         map[string]any
+	APIVersion   map[string]any
 	Resource     map[string]any
 	containers   struct {
 		containers []coreV1.Container
@@ -62,6 +74,7 @@ type (
 	AlertManager            monitoringV1.Alertmanager
 	Prometheus              monitoringV1.Prometheus
 	ThanosRuler             monitoringV1.ThanosRuler
+	PrometheusAgent         monitoringV1Alpha1.PrometheusAgent
 	Grafana                 grafanaBetaV1.Grafana
 	Thanos                  thanosAlphaV1.Thanos
 	ThanosReceiver          thanosAlphaV1.Receiver
@@ -82,15 +95,62 @@ type ImagesInterface interface {
 }
 
 // Image holds information of images retrieved.
+// Type is set only for images that are not container images or are not part of the manifests, ex: ImageTypeVMDisk, ImageTypeInjected, ImageTypeDefaulted, ImageTypeOptional.
+// Defaulted lists the components of the resource that do not set an image explicitly, the images for which are picked by its operator.
 type Image struct {
-	Kind  string   `json:"kind,omitempty"  yaml:"kind,omitempty"`
-	Name  string   `json:"name,omitempty"  yaml:"name,omitempty"`
-	Image []string `json:"image,omitempty" yaml:"image,omitempty"`
+	Kind      string   `json:"kind,omitempty"      yaml:"kind,omitempty"`
+	Name      string   `json:"name,omitempty"      yaml:"name,omitempty"`
+	Type      string   `json:"type,omitempty"      yaml:"type,omitempty"`
+	Image     []string `json:"image,omitempty"     yaml:"image,omitempty"`
+	Defaulted []string `json:"defaulted,omitempty" yaml:"defaulted,omitempty"`
+	// ImageStreamTags, ImageStreamTagAliases and ImageStreamTagRefs are used for resolving OpenShift ImageStreamTag references, see ResolveImageStreamTags.
+	ImageStreamTags       map[string]string `json:"-" yaml:"-"`
+	ImageStreamTagAliases map[string]string `json:"-" yaml:"-"`
+	ImageStreamTagRefs    []string          `json:"-" yaml:"-"`
+	// RuntimeConfigRefs is used for linking Crossplane packages to their runtime configs, see ResolveRuntimeConfigs.
+	RuntimeConfigRefs []string `json:"-" yaml:"-"`
+	// DefaultedSpecs holds the parts of the image set for the Defaulted components, see ResolveDefaults.
+	DefaultedSpecs map[string]ImageSpec `json:"-" yaml:"-"`
 }
 
 type Images struct {
-	ImagesFromRelease any    `json:"images_from_release,omitempty" yaml:"images_from_release,omitempty"`
-	NameSpace         string `json:"name_space,omitempty"          yaml:"name_space,omitempty"`
+	ImagesFromRelease any         `json:"images_from_release,omitempty" yaml:"images_from_release,omitempty"`
+	NameSpace         string      `json:"name_space,omitempty"          yaml:"name_space,omitempty"`
+	Warnings          []Warning   `json:"warnings,omitempty"            yaml:"warnings,omitempty"`
+	Coverage          []Skipped   `json:"coverage,omitempty"            yaml:"coverage,omitempty"`
+	Provenance        *Provenance `json:"provenance,omitempty"          yaml:"provenance,omitempty"`
+	Placeholders      []string    `json:"placeholders,omitempty"        yaml:"placeholders,omitempty"`
+}
+
+// VariantImage holds an image along with the variants (named sets of values) the chart uses it with.
+type VariantImage struct {
+	Image    string   `json:"image,omitempty"    yaml:"image,omitempty"`
+	Variants []string `json:"variants,omitempty" yaml:"variants,omitempty"`
+}
+
+// Provenance holds the result of verifying the chart against its provenance file, when enabled with --verify.
+type Provenance struct {
+	Chart       string   `json:"chart,omitempty"       yaml:"chart,omitempty"`
+	Verified    bool     `json:"verified"              yaml:"verified"`
+	SignedBy    []string `json:"signed_by,omitempty"   yaml:"signed_by,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+	FileHash    string   `json:"file_hash,omitempty"   yaml:"file_hash,omitempty"`
+	Message     string   `json:"message,omitempty"     yaml:"message,omitempty"`
+}
+
+// Skipped holds a manifest skipped as its kind is not one of the kinds images are extracted from.
+// ImageFields lists the paths of the fields of it that look like images, those hint at the kinds missing extractors.
+type Skipped struct {
+	Kind        string   `json:"kind,omitempty"         yaml:"kind,omitempty"`
+	Name        string   `json:"name,omitempty"         yaml:"name,omitempty"`
+	ImageFields []string `json:"image_fields,omitempty" yaml:"image_fields,omitempty"`
+}
+
+// Warning holds the failure of extracting images from a manifest, or of rendering a chart, that did not abort the run.
+type Warning struct {
+	Kind    string `json:"kind,omitempty"    yaml:"kind,omitempty"`
+	Name    string `json:"name,omitempty"    yaml:"name,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
 }
 
 func (name *Name) Get(dataMap string, log *logrus.Logger) (string, error) {
@@ -138,6 +198,21 @@ func (kin *Kind) Get(dataMap string, log *logrus.Logger) (string, error) {
 	return kind, nil
 }
 
+func (api *APIVersion) Get(dataMap string, log *logrus.Logger) (string, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), api); err != nil {
+		return "", err
+	}
+
+	apiVersion, apiVersionExists := (*api)[kubeAPIVersion].(string)
+	if !apiVersionExists {
+		log.Warn("failed to get 'apiVersion' from the manifest")
+
+		return "", nil
+	}
+
+	return apiVersion, nil
+}
+
 // Get identifies images from Deployments.
 func (dep *Deployments) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
 	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
@@ -278,12 +353,18 @@ func (dep *AlertManager) Get(dataMap string, _ string, _ *logrus.Logger) (*Image
 		return nil, err
 	}
 
+	depContainers := containers{append(dep.Spec.Containers, dep.Spec.InitContainers...)}
+
 	images := &Image{
 		Kind:  monitoringV1.AlertmanagersKind,
 		Name:  dep.Name,
-		Image: []string{*dep.Spec.Image},
+		Image: depContainers.getDefinedImages(),
 	}
 
+	images.addImageOrDefault(buildImagePath(ptr.Deref(dep.Spec.Image, ""), dep.Spec.BaseImage, dep.Spec.Version, dep.Spec.Tag, dep.Spec.SHA),
+		componentAlertManager)
+	images.setDefaultedSpec(componentAlertManager, ImageSpec{BaseImage: dep.Spec.BaseImage, Version: dep.Spec.Version, Tag: dep.Spec.Tag, SHA: dep.Spec.SHA})
+
 	return images, nil
 }
 
@@ -293,63 +374,98 @@ func (dep *Prometheus) Get(dataMap string, _ string, _ *logrus.Logger) (*Image,
 		return nil, err
 	}
 
-	var imageNames []string
-
 	depContainers := containers{append(dep.Spec.Containers, dep.Spec.InitContainers...)}
 
-	imageNames = append(imageNames, depContainers.getImages()...)
-	imageNames = append(imageNames, *dep.Spec.Image, *dep.Spec.Thanos.Image)
-
 	images := &Image{
 		Kind:  monitoringV1.PrometheusesKind,
 		Name:  dep.Name,
-		Image: imageNames,
+		Image: depContainers.getDefinedImages(),
+	}
+
+	images.addImageOrDefault(buildImagePath(ptr.Deref(dep.Spec.Image, ""), dep.Spec.BaseImage, dep.Spec.Version, dep.Spec.Tag, dep.Spec.SHA),
+		componentPrometheus)
+	images.setDefaultedSpec(componentPrometheus, ImageSpec{BaseImage: dep.Spec.BaseImage, Version: dep.Spec.Version, Tag: dep.Spec.Tag, SHA: dep.Spec.SHA})
+
+	if thanos := dep.Spec.Thanos; thanos != nil {
+		thanosSpec := ImageSpec{
+			BaseImage: ptr.Deref(thanos.BaseImage, ""),
+			Version:   ptr.Deref(thanos.Version, ""),
+			Tag:       ptr.Deref(thanos.Tag, ""),
+			SHA:       ptr.Deref(thanos.SHA, ""),
+		}
+
+		images.addImageOrDefault(buildImagePath(ptr.Deref(thanos.Image, ""), thanosSpec.BaseImage, thanosSpec.Version, thanosSpec.Tag, thanosSpec.SHA),
+			componentThanosSidecar)
+		images.setDefaultedSpec(componentThanosSidecar, thanosSpec)
 	}
 
 	return images, nil
 }
 
+// Get identifies images from PrometheusAgent.
+func (dep *PrometheusAgent) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	depContainers := containers{append(dep.Spec.Containers, dep.Spec.InitContainers...)}
+
+	images := &Image{
+		Kind:  monitoringV1Alpha1.PrometheusAgentsKind,
+		Name:  dep.Name,
+		Image: depContainers.getDefinedImages(),
+	}
+
+	images.addImageOrDefault(ptr.Deref(dep.Spec.Image, ""), componentPrometheus)
+	images.setDefaultedSpec(componentPrometheus, ImageSpec{Version: dep.Spec.Version})
+
+	return images, nil
+}
+
 // Get identifies images from ThanosRuler.
 func (dep *ThanosRuler) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
 	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
 		return nil, err
 	}
 
-	var imageNames []string
-
 	depContainers := containers{append(dep.Spec.Containers, dep.Spec.InitContainers...)}
 
-	imageNames = append(imageNames, depContainers.getImages()...)
-	imageNames = append(imageNames, dep.Spec.Image)
-
 	images := &Image{
 		Kind:  monitoringV1.ThanosRulerKind,
 		Name:  dep.Name,
-		Image: imageNames,
+		Image: depContainers.getDefinedImages(),
 	}
 
+	images.addImageOrDefault(dep.Spec.Image, componentThanos)
+	images.setDefaultedSpec(componentThanos, ImageSpec{Version: ptr.Deref(dep.Spec.Version, "")})
+
 	return images, nil
 }
 
-// Get identifies images from Grafana.
+// Get identifies images from Grafana of apiVersion grafana.integreatly.org/v1beta1.
+// For the legacy apiVersion integreatly.org/v1alpha1 use GrafanaV1Alpha1 instead, see NewGrafanaForAPIVersion.
 func (dep *Grafana) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
 	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
 		return nil, err
 	}
 
-	if dep.APIVersion == "integreatly.org/v1alpha1" {
+	if dep.APIVersion == grafanaV1Alpha1APIVersion {
 		return nil, &imgErrors.GrafanaAPIVersionSupportError{
 			Message: fmt.Sprintf("plugin supports the latest api version and '%s' is not supported", dep.APIVersion),
 		}
 	}
 
-	grafanaDeployment := dep.Spec.Deployment.Spec.Template.Spec
-	depContainers := containers{append(grafanaDeployment.Containers, grafanaDeployment.InitContainers...)}
+	grafanaContainers := make([]coreV1.Container, 0)
+
+	if deployment := dep.Spec.Deployment; deployment != nil && deployment.Spec.Template != nil && deployment.Spec.Template.Spec != nil {
+		grafanaContainers = append(grafanaContainers, deployment.Spec.Template.Spec.Containers...)
+		grafanaContainers = append(grafanaContainers, deployment.Spec.Template.Spec.InitContainers...)
+	}
 
 	images := &Image{
 		Kind:  KindGrafana,
 		Name:  dep.Name,
-		Image: depContainers.getImages(),
+		Image: containers{grafanaContainers}.getDefinedImages(),
 	}
 
 	return images, nil
@@ -478,9 +594,10 @@ func (dep *CrossPlaneProvider) Get(dataMap string, _ string, _ *logrus.Logger) (
 	}
 
 	images := &Image{
-		Kind:  KindCrossPlaneProvider,
-		Name:  dep.Name,
-		Image: []string{dep.Spec.Package},
+		Kind:              KindCrossPlaneProvider,
+		Name:              dep.Name,
+		Image:             []string{dep.Spec.Package},
+		RuntimeConfigRefs: getRuntimeConfigRefs(dep.Spec.PackageRuntimeSpec),
 	}
 
 	return images, nil
@@ -508,9 +625,10 @@ func (dep *CrossPlaneFunction) Get(dataMap string, _ string, _ *logrus.Logger) (
 	}
 
 	images := &Image{
-		Kind:  KindCrossPlaneFunction,
-		Name:  dep.Name,
-		Image: []string{dep.Spec.Package},
+		Kind:              KindCrossPlaneFunction,
+		Name:              dep.Name,
+		Image:             []string{dep.Spec.Package},
+		RuntimeConfigRefs: getRuntimeConfigRefs(dep.Spec.PackageRuntimeSpec),
 	}
 
 	return images, nil
@@ -561,6 +679,11 @@ func NewPrometheus() ImagesInterface {
 	return &Prometheus{}
 }
 
+// NewPrometheusAgent returns new instance of PrometheusAgent.
+func NewPrometheusAgent() ImagesInterface {
+	return &PrometheusAgent{}
+}
+
 // NewThanosRuler returns new instance of ThanosRuler.
 func NewThanosRuler() ImagesInterface {
 	return &ThanosRuler{}
@@ -610,13 +733,25 @@ func NewName() KindInterface {
 	return &Name{}
 }
 
+// NewAPIVersion returns new instance of APIVersion.
+func NewAPIVersion() KindInterface {
+	return &APIVersion{}
+}
+
 func SupportedKinds() []string {
 	kinds := []string{
 		KindDeployment, KindStatefulSet, KindDaemonSet,
 		KindCronJob, KindJob, KindReplicaSet, KindPod,
 		monitoringV1.AlertmanagersKind, monitoringV1.PrometheusesKind, monitoringV1.ThanosRulerKind,
+		monitoringV1Alpha1.PrometheusAgentsKind,
 		KindGrafana, KindThanos, KindThanosReceiver, KindConfigMap,
 		KindCrossPlaneProvider, KindCrossPlaneConfiguration, KindCrossPlaneFunction,
+		KindCrossPlaneDeploymentRuntimeConfig, KindCrossPlaneControllerConfig,
+		KindSparkApplication, KindScheduledSparkApplication, KindRayCluster, KindRayJob, KindRayService,
+		KindTFJob, KindPyTorchJob,
+		KindElasticsearch, KindKibana, KindCNPGCluster, KindKafka,
+		KindDeploymentConfig, KindBuildConfig, KindImageStream,
+		KindVirtualMachine, KindVirtualMachineInstance, KindDataVolume,
 	}
 
 	return kinds
@@ -648,6 +783,53 @@ func (cont containers) getImages() []string {
 	return images
 }
 
+// addImageOrDefault records the image when set, else marks the component as the one relying on operator defaults.
+func (img *Image) addImageOrDefault(image, component string) {
+	if len(image) != 0 {
+		img.Image = append(img.Image, image)
+
+		return
+	}
+
+	img.Defaulted = append(img.Defaulted, component)
+}
+
+// Containers of operator managed pod templates usually only patch the operator generated pod
+// and do not carry an image, such entries are dropped.
+func (cont containers) getDefinedImages() []string {
+	images := make([]string, 0)
+
+	for _, image := range cont.getImages() {
+		if len(strings.TrimSpace(image)) != 0 {
+			images = append(images, image)
+		}
+	}
+
+	return images
+}
+
+// buildImagePath composes the image the same way prometheus-operator does, image when set takes precedence over
+// baseImage, while sha takes precedence over tag which in turn takes precedence over version.
+// An empty string is returned when the image cannot be determined from the spec alone.
+func buildImagePath(image, baseImage, version, tag, sha string) string {
+	if len(image) != 0 {
+		return image
+	}
+
+	switch {
+	case len(baseImage) == 0:
+		return ""
+	case len(sha) != 0:
+		return baseImage + "@sha256:" + sha
+	case len(tag) != 0:
+		return baseImage + ":" + tag
+	case len(version) != 0:
+		return baseImage + ":" + version
+	default:
+		return ""
+	}
+}
+
 //nolint:nonamedreturns
 func GetImage(data map[string]any, key, regex string, log *logrus.Logger) (values []string, valuesFound bool) {
 	for dataKey, dataValue := range data {
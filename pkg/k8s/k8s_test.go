@@ -59,3 +59,653 @@ config:
 		}, valueFound)
 	})
 }
+
+func TestDataPlatformImages(t *testing.T) {
+	log := logrus.New()
+
+	t.Run("should be able to fetch driver, executor and application images from SparkApplication", func(t *testing.T) {
+		sparkApplication := `apiVersion: sparkoperator.k8s.io/v1beta2
+kind: SparkApplication
+metadata:
+  name: spark-pi
+spec:
+  image: spark:3.5.0
+  driver:
+    image: spark-driver:3.5.0
+    sidecars:
+      - name: logger
+        image: fluent-bit:2.2.0
+  executor:
+    image: spark-executor:3.5.0`
+
+		img, err := k8s.NewSparkApplication().Get(sparkApplication, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, "spark-pi", img.Name)
+		assert.ElementsMatch(t, []string{"spark:3.5.0", "spark-driver:3.5.0", "spark-executor:3.5.0", "fluent-bit:2.2.0"}, img.Image)
+	})
+
+	t.Run("should be able to fetch head and worker group images from RayCluster", func(t *testing.T) {
+		rayCluster := `apiVersion: ray.io/v1
+kind: RayCluster
+metadata:
+  name: raycluster
+spec:
+  headGroupSpec:
+    template:
+      spec:
+        containers:
+          - name: ray-head
+            image: rayproject/ray:2.9.0
+  workerGroupSpecs:
+    - groupName: gpu
+      template:
+        spec:
+          containers:
+            - name: ray-worker
+              image: rayproject/ray:2.9.0-gpu`
+
+		img, err := k8s.NewRayCluster().Get(rayCluster, "", log)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"rayproject/ray:2.9.0", "rayproject/ray:2.9.0-gpu"}, img.Image)
+	})
+
+	t.Run("should be able to fetch replica images from PyTorchJob", func(t *testing.T) {
+		pytorchJob := `apiVersion: kubeflow.org/v1
+kind: PyTorchJob
+metadata:
+  name: mnist
+spec:
+  pytorchReplicaSpecs:
+    Master:
+      template:
+        spec:
+          containers:
+            - name: pytorch
+              image: kubeflow/pytorch-mnist:v1
+    Worker:
+      template:
+        spec:
+          initContainers:
+            - name: init
+              image: busybox:1.36
+          containers:
+            - name: pytorch
+              image: kubeflow/pytorch-mnist:v1`
+
+		img, err := k8s.NewPyTorchJob().Get(pytorchJob, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kubeflow/pytorch-mnist:v1", "kubeflow/pytorch-mnist:v1", "busybox:1.36"}, img.Image)
+	})
+
+	t.Run("should ignore containers without image from SparkApplication and TFJob", func(t *testing.T) {
+		sparkApplication := `apiVersion: sparkoperator.k8s.io/v1beta2
+kind: SparkApplication
+metadata:
+  name: spark-pi
+spec:
+  image: spark:3.5.0
+  driver:
+    sidecars:
+      - name: logger`
+
+		img, err := k8s.NewSparkApplication().Get(sparkApplication, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"spark:3.5.0"}, img.Image)
+
+		tfJob := `apiVersion: kubeflow.org/v1
+kind: TFJob
+metadata:
+  name: mnist
+spec:
+  tfReplicaSpecs:
+    Worker:
+      template:
+        spec:
+          containers:
+            - name: tensorflow
+              image: kubeflow/tf-mnist:v1
+            - name: metrics
+              resources:
+                limits:
+                  memory: 128Mi`
+
+		img, err = k8s.NewTFJob().Get(tfJob, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kubeflow/tf-mnist:v1"}, img.Image)
+	})
+}
+
+func TestStatefulServiceImages(t *testing.T) {
+	log := logrus.New()
+
+	t.Run("should flag Strimzi components relying on operator default images", func(t *testing.T) {
+		kafka := `apiVersion: kafka.strimzi.io/v1beta2
+kind: Kafka
+metadata:
+  name: my-cluster
+spec:
+  kafka:
+    image: quay.io/strimzi/kafka:0.40.0-kafka-3.7.0
+    replicas: 3
+  zookeeper:
+    replicas: 3
+  entityOperator:
+    topicOperator: {}`
+
+		img, err := k8s.NewKafka().Get(kafka, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"quay.io/strimzi/kafka:0.40.0-kafka-3.7.0"}, img.Image)
+		assert.Equal(t, []string{"zookeeper", "topic-operator"}, img.Defaulted)
+	})
+
+	t.Run("should fetch images from Elasticsearch and ignore pod template containers without image", func(t *testing.T) {
+		elasticsearch := `apiVersion: elasticsearch.k8s.elastic.co/v1
+kind: Elasticsearch
+metadata:
+  name: quickstart
+spec:
+  version: 8.12.0
+  image: docker.elastic.co/elasticsearch/elasticsearch:8.12.0
+  nodeSets:
+    - name: default
+      podTemplate:
+        spec:
+          initContainers:
+            - name: sysctl
+              image: busybox:1.36
+          containers:
+            - name: elasticsearch`
+
+		img, err := k8s.NewElasticsearch().Get(elasticsearch, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"busybox:1.36", "docker.elastic.co/elasticsearch/elasticsearch:8.12.0"}, img.Image)
+		assert.Empty(t, img.Defaulted)
+	})
+
+	t.Run("should fetch images from Kibana and flag Kibana without image as defaulted", func(t *testing.T) {
+		kibana := `apiVersion: kibana.k8s.elastic.co/v1
+kind: Kibana
+metadata:
+  name: quickstart
+spec:
+  version: 8.12.0
+  podTemplate:
+    spec:
+      containers:
+        - name: kibana
+        - name: oauth-proxy
+          image: quay.io/oauth2-proxy/oauth2-proxy:v7.5.1`
+
+		img, err := k8s.NewKibana().Get(kibana, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"quay.io/oauth2-proxy/oauth2-proxy:v7.5.1"}, img.Image)
+		assert.Equal(t, []string{"kibana"}, img.Defaulted)
+	})
+
+	t.Run("should fail on kind Cluster that does not belong to CloudNativePG", func(t *testing.T) {
+		cluster := `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: workload`
+
+		assert.False(t, k8s.IsCNPGCluster("cluster.x-k8s.io/v1beta1"))
+
+		_, err := k8s.NewCNPGCluster().Get(cluster, "", log)
+		assert.EqualError(t, err, "kind 'Cluster' of apiVersion 'cluster.x-k8s.io/v1beta1' is not a CloudNativePG cluster")
+	})
+
+	t.Run("should flag CloudNativePG Cluster without imageName as defaulted", func(t *testing.T) {
+		cluster := `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: pg
+spec:
+  instances: 3`
+
+		img, err := k8s.NewCNPGCluster().Get(cluster, "", log)
+		require.NoError(t, err)
+		assert.Empty(t, img.Image)
+		assert.Equal(t, []string{"postgresql"}, img.Defaulted)
+	})
+}
+
+func TestResolveImageStreamTags(t *testing.T) {
+	log := logrus.New()
+
+	imageStream := `apiVersion: image.openshift.io/v1
+kind: ImageStream
+metadata:
+  name: ruby
+spec:
+  tags:
+    - name: "3.1"
+      from:
+        kind: DockerImage
+        name: registry.access.redhat.com/ubi8/ruby-31:latest
+    - name: local
+      from:
+        kind: ImageStreamTag
+        name: ruby:3.1`
+
+	buildConfig := `apiVersion: build.openshift.io/v1
+kind: BuildConfig
+metadata:
+  name: app
+spec:
+  strategy:
+    sourceStrategy:
+      from:
+        kind: ImageStreamTag
+        name: ruby:3.1
+  source:
+    images:
+      - from:
+          kind: DockerImage
+          name: quay.io/example/assets:v1`
+
+	deploymentConfig := `apiVersion: apps.openshift.io/v1
+kind: DeploymentConfig
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: " "
+  triggers:
+    - type: ImageChange
+      imageChangeParams:
+        containerNames: [app]
+        from:
+          kind: ImageStreamTag
+          name: app
+    - type: ImageChange
+      imageChangeParams:
+        containerNames: [app]
+        from:
+          kind: ImageStreamTag
+          name: ruby:local`
+
+	t.Run("should resolve ImageStreamTag references to the external images defined in the same render", func(t *testing.T) {
+		imageStreamImages, err := k8s.NewImageStream().Get(imageStream, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"registry.access.redhat.com/ubi8/ruby-31:latest"}, imageStreamImages.Image)
+
+		buildConfigImages, err := k8s.NewBuildConfig().Get(buildConfig, "", log)
+		require.NoError(t, err)
+
+		deploymentConfigImages, err := k8s.NewDeploymentConfig().Get(deploymentConfig, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app:latest", "ruby:local"}, deploymentConfigImages.ImageStreamTagRefs)
+
+		k8s.ResolveImageStreamTags([]*k8s.Image{imageStreamImages, buildConfigImages, deploymentConfigImages}, log)
+		assert.Equal(t, []string{"quay.io/example/assets:v1", "registry.access.redhat.com/ubi8/ruby-31:latest"}, buildConfigImages.Image)
+		assert.Equal(t, []string{"registry.access.redhat.com/ubi8/ruby-31:latest"}, deploymentConfigImages.Image)
+	})
+
+	t.Run("should resolve ImageStreamTag references by the namespace of the reference or of the referring resource", func(t *testing.T) {
+		namespacedImageStream := `apiVersion: image.openshift.io/v1
+kind: ImageStream
+metadata:
+  name: nginx
+  namespace: shared
+spec:
+  tags:
+    - name: stable
+      from:
+        kind: DockerImage
+        name: docker.io/library/nginx:1.27
+    - name: latest
+      from:
+        kind: ImageStreamTag
+        name: stable`
+
+		namespacedBuildConfig := `apiVersion: build.openshift.io/v1
+kind: BuildConfig
+metadata:
+  name: web
+  namespace: apps
+spec:
+  strategy:
+    dockerStrategy:
+      from:
+        kind: ImageStreamTag
+        namespace: shared
+        name: nginx
+  source:
+    images:
+      - from:
+          kind: ImageStreamTag
+          name: nginx:stable`
+
+		imageStreamImages, err := k8s.NewImageStream().Get(namespacedImageStream, "", log)
+		require.NoError(t, err)
+
+		buildConfigImages, err := k8s.NewBuildConfig().Get(namespacedBuildConfig, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"shared/nginx:latest", "apps/nginx:stable"}, buildConfigImages.ImageStreamTagRefs)
+
+		k8s.ResolveImageStreamTags([]*k8s.Image{imageStreamImages, buildConfigImages}, log)
+		assert.Equal(t, []string{"docker.io/library/nginx:1.27"}, buildConfigImages.Image)
+	})
+}
+
+func TestVirtualMachineImages(t *testing.T) {
+	t.Run("should fetch container disks and registry data volumes from VirtualMachine as vm disk images", func(t *testing.T) {
+		virtualMachine := `apiVersion: kubevirt.io/v1
+kind: VirtualMachine
+metadata:
+  name: fedora
+spec:
+  dataVolumeTemplates:
+    - metadata:
+        name: fedora-dv
+      spec:
+        source:
+          registry:
+            url: docker://quay.io/containerdisks/fedora:39
+  template:
+    spec:
+      volumes:
+        - name: rootdisk
+          dataVolume:
+            name: fedora-dv
+        - name: cloudinit
+          containerDisk:
+            image: quay.io/kubevirt/cirros-container-disk-demo:v1.1.0`
+
+		img, err := k8s.NewVirtualMachine().Get(virtualMachine, "", logrus.New())
+		require.NoError(t, err)
+		assert.Equal(t, k8s.ImageTypeVMDisk, img.Type)
+		assert.Equal(t, []string{"quay.io/kubevirt/cirros-container-disk-demo:v1.1.0", "quay.io/containerdisks/fedora:39"}, img.Image)
+	})
+
+	t.Run("should skip the data volumes with empty or non registry sources", func(t *testing.T) {
+		virtualMachine := `apiVersion: kubevirt.io/v1
+kind: VirtualMachine
+metadata:
+  name: fedora
+spec:
+  dataVolumeTemplates:
+    - metadata:
+        name: empty-dv
+      spec:
+        source:
+          registry:
+            url: ""
+    - metadata:
+        name: archive-dv
+      spec:
+        source:
+          registry:
+            url: oci-archive:///var/lib/images/fedora.tar
+    - metadata:
+        name: scheme-only-dv
+      spec:
+        source:
+          registry:
+            url: docker://`
+
+		img, err := k8s.NewVirtualMachine().Get(virtualMachine, "", logrus.New())
+		require.NoError(t, err)
+		assert.Empty(t, img.Image)
+	})
+}
+
+func TestPrometheusOperatorImages(t *testing.T) {
+	log := logrus.New()
+
+	t.Run("should compose Alertmanager image from baseImage and version along with its sidecars", func(t *testing.T) {
+		alertManager := `apiVersion: monitoring.coreos.com/v1
+kind: Alertmanager
+metadata:
+  name: main
+spec:
+  baseImage: quay.io/prometheus/alertmanager
+  version: v0.27.0
+  containers:
+    - name: alertmanager
+    - name: oauth-proxy
+      image: quay.io/oauth2-proxy/oauth2-proxy:v7.5.1`
+
+		img, err := k8s.NewAlertManager().Get(alertManager, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"quay.io/oauth2-proxy/oauth2-proxy:v7.5.1", "quay.io/prometheus/alertmanager:v0.27.0"}, img.Image)
+	})
+
+	t.Run("should not fail on Prometheus with optional images omitted", func(t *testing.T) {
+		prometheus := `apiVersion: monitoring.coreos.com/v1
+kind: Prometheus
+metadata:
+  name: k8s
+spec:
+  thanos:
+    baseImage: quay.io/thanos/thanos
+    sha: 3a3b4c5d`
+
+		img, err := k8s.NewPrometheus().Get(prometheus, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"quay.io/thanos/thanos@sha256:3a3b4c5d"}, img.Image)
+		assert.Equal(t, []string{"prometheus"}, img.Defaulted)
+	})
+
+	t.Run("should fetch images from PrometheusAgent", func(t *testing.T) {
+		prometheusAgent := `apiVersion: monitoring.coreos.com/v1alpha1
+kind: PrometheusAgent
+metadata:
+  name: agent
+spec:
+  image: quay.io/prometheus/prometheus:v2.51.0`
+
+		img, err := k8s.NewPrometheusAgent().Get(prometheusAgent, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"quay.io/prometheus/prometheus:v2.51.0"}, img.Image)
+	})
+}
+
+func TestGrafanaImages(t *testing.T) {
+	log := logrus.New()
+
+	t.Run("should fetch the images overridden under the deployment of legacy Grafana", func(t *testing.T) {
+		grafana := `apiVersion: integreatly.org/v1alpha1
+kind: Grafana
+metadata:
+  name: legacy
+spec:
+  baseImage: grafana/grafana:7.5.17
+  deployment:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: grafana
+            - name: oauth-proxy
+              image: quay.io/oauth2-proxy/oauth2-proxy:v7.5.1
+          initContainers:
+            - name: wait-for-db
+              image: busybox:1.36`
+
+		img, err := k8s.NewGrafanaV1Alpha1().Get(grafana, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"quay.io/oauth2-proxy/oauth2-proxy:v7.5.1", "busybox:1.36", "grafana/grafana:7.5.17"}, img.Image)
+	})
+
+	t.Run("should fetch the images overridden under the deployment of Grafana", func(t *testing.T) {
+		grafana := `apiVersion: grafana.integreatly.org/v1beta1
+kind: Grafana
+metadata:
+  name: grafana
+spec:
+  deployment:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: grafana
+              image: grafana/grafana:10.4.0
+          initContainers:
+            - name: plugins
+              image: busybox:1.36`
+
+		img, err := k8s.NewGrafana().Get(grafana, "", log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"grafana/grafana:10.4.0", "busybox:1.36"}, img.Image)
+	})
+
+	t.Run("should not flag Grafana without images as defaulted, as no default of grafana-operator is known", func(t *testing.T) {
+		grafana := `apiVersion: grafana.integreatly.org/v1beta1
+kind: Grafana
+metadata:
+  name: grafana
+spec:
+  deployment:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: grafana
+              resources:
+                limits:
+                  memory: 512Mi`
+
+		img, err := k8s.NewGrafana().Get(grafana, "", log)
+		require.NoError(t, err)
+		assert.Empty(t, img.Image)
+		assert.Empty(t, img.Defaulted)
+
+		legacy, err := k8s.NewGrafanaV1Alpha1().Get("apiVersion: integreatly.org/v1alpha1\nkind: Grafana\nmetadata:\n  name: legacy", "", log)
+		require.NoError(t, err)
+		assert.Empty(t, legacy.Image)
+		assert.Empty(t, legacy.Defaulted)
+	})
+}
+
+func TestResolveRuntimeConfigs(t *testing.T) {
+	log := logrus.New()
+
+	t.Run("should link runtime config images to the crossplane packages referring them", func(t *testing.T) {
+		provider := `apiVersion: pkg.crossplane.io/v1
+kind: Provider
+metadata:
+  name: provider-aws
+spec:
+  package: xpkg.upbound.io/crossplane-contrib/provider-aws:v0.39.0`
+
+		function := `apiVersion: pkg.crossplane.io/v1
+kind: Function
+metadata:
+  name: function-patch-and-transform
+spec:
+  package: xpkg.upbound.io/crossplane-contrib/function-patch-and-transform:v0.1.4
+  runtimeConfigRef:
+    name: function-runtime`
+
+		runtimeConfig := `apiVersion: pkg.crossplane.io/v1beta1
+kind: DeploymentRuntimeConfig
+metadata:
+  name: function-runtime
+spec:
+  deploymentTemplate:
+    spec:
+      selector: {}
+      template:
+        spec:
+          containers:
+            - name: package-runtime
+              image: registry.example.com/function-patch-and-transform:v0.1.4`
+
+		providerImages, err := k8s.NewCrossPlaneProvider().Get(provider, "", log)
+		require.NoError(t, err)
+
+		functionImages, err := k8s.NewCrossPlaneFunction().Get(function, "", log)
+		require.NoError(t, err)
+
+		runtimeConfigImages, err := k8s.NewCrossPlaneDeploymentRuntimeConfig().Get(runtimeConfig, "", log)
+		require.NoError(t, err)
+
+		k8s.ResolveRuntimeConfigs([]*k8s.Image{providerImages, functionImages, runtimeConfigImages}, log)
+		assert.Equal(t, []string{"xpkg.upbound.io/crossplane-contrib/provider-aws:v0.39.0"}, providerImages.Image)
+		assert.Equal(t, []string{
+			"xpkg.upbound.io/crossplane-contrib/function-patch-and-transform:v0.1.4",
+			"registry.example.com/function-patch-and-transform:v0.1.4",
+		}, functionImages.Image)
+	})
+}
+
+func TestResolveDefaults(t *testing.T) {
+	log := logrus.New()
+
+	t.Run("should resolve the images operators default to, from the version set in the resources", func(t *testing.T) {
+		prometheus := `apiVersion: monitoring.coreos.com/v1
+kind: Prometheus
+metadata:
+  name: prometheus
+spec:
+  version: v2.53.0
+  thanos:
+    baseImage: registry.example.com/thanos/thanos`
+
+		alertManager := `apiVersion: monitoring.coreos.com/v1
+kind: Alertmanager
+metadata:
+  name: alertmanager
+spec:
+  image: quay.io/prometheus/alertmanager:v0.27.0`
+
+		elasticsearch := `apiVersion: elasticsearch.k8s.elastic.co/v1
+kind: Elasticsearch
+metadata:
+  name: quickstart
+spec:
+  version: 8.15.0`
+
+		images := make([]*k8s.Image, 0)
+
+		for _, manifest := range []struct {
+			extractor k8s.ImagesInterface
+			manifest  string
+		}{
+			{extractor: k8s.NewPrometheus(), manifest: prometheus},
+			{extractor: k8s.NewAlertManager(), manifest: alertManager},
+			{extractor: k8s.NewElasticsearch(), manifest: elasticsearch},
+		} {
+			img, err := manifest.extractor.Get(manifest.manifest, "", log)
+			require.NoError(t, err)
+
+			images = append(images, img)
+		}
+
+		expected := []*k8s.Image{
+			{
+				Kind:  "Prometheus",
+				Name:  "prometheus",
+				Type:  k8s.ImageTypeDefaulted,
+				Image: []string{"quay.io/prometheus/prometheus:v2.53.0", "registry.example.com/thanos/thanos:v0.39.2"},
+			},
+			{
+				Kind:  "Elasticsearch",
+				Name:  "quickstart",
+				Type:  k8s.ImageTypeDefaulted,
+				Image: []string{"docker.elastic.co/elasticsearch/elasticsearch:8.15.0"},
+			},
+		}
+		assert.Equal(t, expected, k8s.ResolveDefaults(images, k8s.DefaultOperatorImages(), log))
+	})
+
+	t.Run("should honour the overridden defaults", func(t *testing.T) {
+		thanosRuler := `apiVersion: monitoring.coreos.com/v1
+kind: ThanosRuler
+metadata:
+  name: thanos-ruler`
+
+		img, err := k8s.NewThanosRuler().Get(thanosRuler, "", log)
+		require.NoError(t, err)
+
+		defaults := []k8s.OperatorDefault{{Kind: "ThanosRuler", Component: "thanos", BaseImage: "mirror.example.com/thanos/thanos", Version: "v0.36.1"}}
+
+		resolved := k8s.ResolveDefaults([]*k8s.Image{img}, defaults, log)
+		require.Len(t, resolved, 1)
+		assert.Equal(t, []string{"mirror.example.com/thanos/thanos:v0.36.1"}, resolved[0].Image)
+	})
+}
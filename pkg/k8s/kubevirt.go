@@ -0,0 +1,169 @@
+package k8s
+
+import (
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/sirupsen/logrus"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	KindVirtualMachine         = "VirtualMachine"
+	KindVirtualMachineInstance = "VirtualMachineInstance"
+	KindDataVolume             = "DataVolume"
+	// ImageTypeVMDisk is the type of images that are pulled as virtual machine disks rather run as containers.
+	ImageTypeVMDisk = "vm-disk"
+	registryScheme  = "docker://"
+)
+
+type (
+	VirtualMachine struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			Template *struct {
+				Spec virtualMachineInstanceSpec `json:"spec,omitempty"`
+			} `json:"template,omitempty"`
+			DataVolumeTemplates []struct {
+				Spec dataVolumeSpec `json:"spec,omitempty"`
+			} `json:"dataVolumeTemplates,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	VirtualMachineInstance struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              virtualMachineInstanceSpec `json:"spec,omitempty"`
+	}
+	DataVolume struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              dataVolumeSpec `json:"spec,omitempty"`
+	}
+	virtualMachineInstanceSpec struct {
+		Domain struct {
+			Firmware *struct {
+				KernelBoot *struct {
+					Container *struct {
+						Image string `json:"image,omitempty"`
+					} `json:"container,omitempty"`
+				} `json:"kernelBoot,omitempty"`
+			} `json:"firmware,omitempty"`
+		} `json:"domain,omitempty"`
+		Volumes []struct {
+			ContainerDisk *struct {
+				Image string `json:"image,omitempty"`
+			} `json:"containerDisk,omitempty"`
+		} `json:"volumes,omitempty"`
+	}
+	dataVolumeSpec struct {
+		Source *struct {
+			Registry *struct {
+				URL *string `json:"url,omitempty"`
+			} `json:"registry,omitempty"`
+		} `json:"source,omitempty"`
+	}
+)
+
+// Get identifies disk images from VirtualMachine.
+func (dep *VirtualMachine) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:  KindVirtualMachine,
+		Name:  dep.Name,
+		Type:  ImageTypeVMDisk,
+		Image: make([]string, 0),
+	}
+
+	if dep.Spec.Template != nil {
+		images.Image = append(images.Image, dep.Spec.Template.Spec.getImages()...)
+	}
+
+	for _, dataVolumeTemplate := range dep.Spec.DataVolumeTemplates {
+		images.Image = append(images.Image, dataVolumeTemplate.Spec.getImages()...)
+	}
+
+	return images, nil
+}
+
+// Get identifies disk images from VirtualMachineInstance.
+func (dep *VirtualMachineInstance) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:  KindVirtualMachineInstance,
+		Name:  dep.Name,
+		Type:  ImageTypeVMDisk,
+		Image: dep.Spec.getImages(),
+	}
+
+	return images, nil
+}
+
+// Get identifies disk images from DataVolume.
+func (dep *DataVolume) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:  KindDataVolume,
+		Name:  dep.Name,
+		Type:  ImageTypeVMDisk,
+		Image: dep.Spec.getImages(),
+	}
+
+	return images, nil
+}
+
+// NewVirtualMachine returns new instance of VirtualMachine.
+func NewVirtualMachine() ImagesInterface {
+	return &VirtualMachine{}
+}
+
+// NewVirtualMachineInstance returns new instance of VirtualMachineInstance.
+func NewVirtualMachineInstance() ImagesInterface {
+	return &VirtualMachineInstance{}
+}
+
+// NewDataVolume returns new instance of DataVolume.
+func NewDataVolume() ImagesInterface {
+	return &DataVolume{}
+}
+
+func (spec virtualMachineInstanceSpec) getImages() []string {
+	images := make([]string, 0)
+
+	for _, volume := range spec.Volumes {
+		if volume.ContainerDisk != nil && len(volume.ContainerDisk.Image) != 0 {
+			images = append(images, volume.ContainerDisk.Image)
+		}
+	}
+
+	firmware := spec.Domain.Firmware
+	if firmware != nil && firmware.KernelBoot != nil && firmware.KernelBoot.Container != nil && len(firmware.KernelBoot.Container.Image) != 0 {
+		images = append(images, firmware.KernelBoot.Container.Image)
+	}
+
+	return images
+}
+
+// Registry sources of CDI are urls of form 'docker://<image>', scheme is stripped to report them as images.
+// Sources of other schemes, ex: 'oci-archive://<path>', are archives on the node rather images pulled from a registry, hence are not reported.
+func (spec dataVolumeSpec) getImages() []string {
+	if spec.Source == nil || spec.Source.Registry == nil || spec.Source.Registry.URL == nil {
+		return nil
+	}
+
+	image, found := strings.CutPrefix(*spec.Source.Registry.URL, registryScheme)
+	if !found || len(image) == 0 {
+		return nil
+	}
+
+	return []string{image}
+}
@@ -0,0 +1,275 @@
+package k8s
+
+import (
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	KindDeploymentConfig  = "DeploymentConfig"
+	KindBuildConfig       = "BuildConfig"
+	KindImageStream       = "ImageStream"
+	referenceDockerImage  = "DockerImage"
+	referenceImageStream  = "ImageStreamTag"
+	imageStreamDefaultTag = "latest"
+)
+
+type (
+	DeploymentConfig struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			Template *coreV1.PodTemplateSpec `json:"template,omitempty"`
+			Triggers []struct {
+				Type              string `json:"type,omitempty"`
+				ImageChangeParams *struct {
+					From coreV1.ObjectReference `json:"from,omitempty"`
+				} `json:"imageChangeParams,omitempty"`
+			} `json:"triggers,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	BuildConfig struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			Source struct {
+				Images []struct {
+					From coreV1.ObjectReference `json:"from,omitempty"`
+				} `json:"images,omitempty"`
+			} `json:"source,omitempty"`
+			Strategy struct {
+				SourceStrategy *buildStrategy `json:"sourceStrategy,omitempty"`
+				DockerStrategy *buildStrategy `json:"dockerStrategy,omitempty"`
+				CustomStrategy *buildStrategy `json:"customStrategy,omitempty"`
+			} `json:"strategy,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	ImageStream struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			Tags []struct {
+				Name string                  `json:"name,omitempty"`
+				From *coreV1.ObjectReference `json:"from,omitempty"`
+			} `json:"tags,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	buildStrategy struct {
+		From *coreV1.ObjectReference `json:"from,omitempty"`
+	}
+)
+
+// Get identifies images from DeploymentConfig.
+func (dep *DeploymentConfig) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:  KindDeploymentConfig,
+		Name:  dep.Name,
+		Image: make([]string, 0),
+	}
+
+	if dep.Spec.Template != nil {
+		depContainers := containers{append(dep.Spec.Template.Spec.Containers, dep.Spec.Template.Spec.InitContainers...)}
+
+		images.Image = append(images.Image, depContainers.getDefinedImages()...)
+		images.Image = append(images.Image, depContainers.getImagesFromArgs()...)
+	}
+
+	for _, trigger := range dep.Spec.Triggers {
+		if trigger.ImageChangeParams == nil {
+			continue
+		}
+
+		images.addObjectReference(trigger.ImageChangeParams.From, dep.Namespace)
+	}
+
+	return images, nil
+}
+
+// Get identifies images from BuildConfig.
+func (dep *BuildConfig) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:  KindBuildConfig,
+		Name:  dep.Name,
+		Image: make([]string, 0),
+	}
+
+	strategy := dep.Spec.Strategy
+	for _, buildStrategy := range []*buildStrategy{strategy.SourceStrategy, strategy.DockerStrategy, strategy.CustomStrategy} {
+		if buildStrategy == nil || buildStrategy.From == nil {
+			continue
+		}
+
+		images.addObjectReference(*buildStrategy.From, dep.Namespace)
+	}
+
+	for _, sourceImage := range dep.Spec.Source.Images {
+		images.addObjectReference(sourceImage.From, dep.Namespace)
+	}
+
+	return images, nil
+}
+
+// Get identifies images from ImageStream, only the tags pointing at an external registry are reported.
+// Tags aliasing other ImageStreamTags are recorded, so that the references to them are resolved as well, see ResolveImageStreamTags.
+func (dep *ImageStream) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:                  KindImageStream,
+		Name:                  dep.Name,
+		Image:                 make([]string, 0),
+		ImageStreamTags:       make(map[string]string),
+		ImageStreamTagAliases: make(map[string]string),
+	}
+
+	for _, tag := range dep.Spec.Tags {
+		if tag.From == nil || len(tag.From.Name) == 0 {
+			continue
+		}
+
+		tagKey := getImageStreamTagKey(dep.Namespace, dep.Name+":"+tag.Name)
+
+		switch tag.From.Kind {
+		case referenceDockerImage:
+			images.Image = append(images.Image, tag.From.Name)
+			images.ImageStreamTags[tagKey] = tag.From.Name
+		case referenceImageStream:
+			// Aliases to the tags of the same ImageStream could name just the tag.
+			name := tag.From.Name
+			if !strings.Contains(name, ":") {
+				name = dep.Name + ":" + name
+			}
+
+			images.ImageStreamTagAliases[tagKey] = getImageStreamTagKey(getReferenceNamespace(*tag.From, dep.Namespace), name)
+		}
+	}
+
+	return images, nil
+}
+
+// NewDeploymentConfig returns new instance of DeploymentConfig.
+func NewDeploymentConfig() ImagesInterface {
+	return &DeploymentConfig{}
+}
+
+// NewBuildConfig returns new instance of BuildConfig.
+func NewBuildConfig() ImagesInterface {
+	return &BuildConfig{}
+}
+
+// NewImageStream returns new instance of ImageStream.
+func NewImageStream() ImagesInterface {
+	return &ImageStream{}
+}
+
+// ResolveImageStreamTags resolves the ImageStreamTag references of DeploymentConfig and BuildConfig to the external
+// images of the ImageStreams defined in the same set of manifests, following the tags aliasing other ImageStreamTags.
+// References pointing at ImageStreams that are not part of it are built or imported in-cluster and cannot be resolved.
+func ResolveImageStreamTags(images []*Image, log *logrus.Logger) {
+	imageStreamTags := make(map[string]string)
+	imageStreamTagAliases := make(map[string]string)
+
+	for _, img := range images {
+		for tag, image := range img.ImageStreamTags {
+			imageStreamTags[tag] = image
+		}
+
+		for tag, alias := range img.ImageStreamTagAliases {
+			imageStreamTagAliases[tag] = alias
+		}
+	}
+
+	for _, img := range images {
+		for _, reference := range img.ImageStreamTagRefs {
+			image, found := resolveImageStreamTag(reference, imageStreamTags, imageStreamTagAliases)
+			if !found {
+				log.Debugf("ImageStreamTag '%s' referred by '%s' of kind '%s' is not defined in the manifests, hence cannot be resolved",
+					reference, img.Name, img.Kind)
+
+				continue
+			}
+
+			img.Image = append(img.Image, image)
+		}
+	}
+}
+
+// resolveImageStreamTag follows the aliases from the tag until the one pointing at an external image, giving up on the cycles.
+// ImageStreams without a namespace are rendered in the namespace of the release, hence they match the references from any namespace.
+func resolveImageStreamTag(tag string, imageStreamTags, imageStreamTagAliases map[string]string) (string, bool) {
+	for range len(imageStreamTagAliases) + 1 {
+		tagKeys := []string{tag}
+		if _, name, found := strings.Cut(tag, "/"); found {
+			tagKeys = append(tagKeys, name)
+		}
+
+		alias := ""
+
+		for _, tagKey := range tagKeys {
+			if image, found := imageStreamTags[tagKey]; found {
+				return image, true
+			}
+
+			if target, found := imageStreamTagAliases[tagKey]; found && len(alias) == 0 {
+				alias = target
+			}
+		}
+
+		if len(alias) == 0 {
+			return "", false
+		}
+
+		tag = alias
+	}
+
+	return "", false
+}
+
+// addObjectReference records the image or the ImageStreamTag the reference points at, the ImageStreamTags are looked up
+// in the namespace of the reference, or else in the namespace of the resource referring it.
+func (img *Image) addObjectReference(reference coreV1.ObjectReference, namespace string) {
+	switch reference.Kind {
+	case referenceDockerImage:
+		if len(reference.Name) != 0 {
+			img.Image = append(img.Image, reference.Name)
+		}
+	case referenceImageStream:
+		name := reference.Name
+		if !strings.Contains(name, ":") {
+			name = name + ":" + imageStreamDefaultTag
+		}
+
+		img.ImageStreamTagRefs = append(img.ImageStreamTagRefs, getImageStreamTagKey(getReferenceNamespace(reference, namespace), name))
+	}
+}
+
+func getReferenceNamespace(reference coreV1.ObjectReference, namespace string) string {
+	if len(reference.Namespace) != 0 {
+		return reference.Namespace
+	}
+
+	return namespace
+}
+
+// getImageStreamTagKey returns the key the ImageStreamTags are looked up by, namespace/name:tag or just name:tag without a namespace.
+func getImageStreamTagKey(namespace, name string) string {
+	if len(namespace) == 0 {
+		return name
+	}
+
+	return namespace + "/" + name
+}
@@ -0,0 +1,216 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	KindElasticsearch      = "Elasticsearch"
+	KindKibana             = "Kibana"
+	KindCNPGCluster        = "Cluster"
+	KindKafka              = "Kafka"
+	cnpgGroup              = "postgresql.cnpg.io"
+	componentElasticsearch = "elasticsearch"
+	componentKibana        = "kibana"
+	componentPostgreSQL    = "postgresql"
+	componentKafka         = "kafka"
+	componentZookeeper     = "zookeeper"
+	componentKafkaExporter = "kafka-exporter"
+	componentCruiseControl = "cruise-control"
+	componentTopicOperator = "topic-operator"
+	componentUserOperator  = "user-operator"
+	componentTLSSidecar    = "tls-sidecar"
+)
+
+type (
+	Elasticsearch struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			Image    string `json:"image,omitempty"`
+			Version  string `json:"version,omitempty"`
+			NodeSets []struct {
+				PodTemplate coreV1.PodTemplateSpec `json:"podTemplate,omitempty"`
+			} `json:"nodeSets,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	Kibana struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			Image       string                 `json:"image,omitempty"`
+			Version     string                 `json:"version,omitempty"`
+			PodTemplate coreV1.PodTemplateSpec `json:"podTemplate,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	CNPGCluster struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			ImageName string `json:"imageName,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	Kafka struct {
+		metaV1.TypeMeta   `json:",inline"`
+		metaV1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			Kafka          *strimziComponent `json:"kafka,omitempty"`
+			Zookeeper      *strimziComponent `json:"zookeeper,omitempty"`
+			EntityOperator *struct {
+				TopicOperator *strimziComponent `json:"topicOperator,omitempty"`
+				UserOperator  *strimziComponent `json:"userOperator,omitempty"`
+				TLSSidecar    *strimziComponent `json:"tlsSidecar,omitempty"`
+			} `json:"entityOperator,omitempty"`
+			KafkaExporter *strimziComponent `json:"kafkaExporter,omitempty"`
+			CruiseControl *strimziComponent `json:"cruiseControl,omitempty"`
+		} `json:"spec,omitempty"`
+	}
+	strimziComponent struct {
+		Image string `json:"image,omitempty"`
+	}
+)
+
+// Get identifies images from Elasticsearch.
+func (dep *Elasticsearch) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	eckContainers := make([]coreV1.Container, 0)
+	for _, nodeSet := range dep.Spec.NodeSets {
+		eckContainers = append(eckContainers, nodeSet.PodTemplate.Spec.Containers...)
+		eckContainers = append(eckContainers, nodeSet.PodTemplate.Spec.InitContainers...)
+	}
+
+	images := &Image{
+		Kind:  KindElasticsearch,
+		Name:  dep.Name,
+		Image: containers{eckContainers}.getDefinedImages(),
+	}
+
+	images.addImageOrDefault(dep.Spec.Image, componentElasticsearch)
+	images.setDefaultedSpec(componentElasticsearch, ImageSpec{Version: dep.Spec.Version})
+
+	return images, nil
+}
+
+// Get identifies images from Kibana.
+func (dep *Kibana) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	depContainers := containers{append(dep.Spec.PodTemplate.Spec.Containers, dep.Spec.PodTemplate.Spec.InitContainers...)}
+
+	images := &Image{
+		Kind:  KindKibana,
+		Name:  dep.Name,
+		Image: depContainers.getDefinedImages(),
+	}
+
+	images.addImageOrDefault(dep.Spec.Image, componentKibana)
+	images.setDefaultedSpec(componentKibana, ImageSpec{Version: dep.Spec.Version})
+
+	return images, nil
+}
+
+// Get identifies images from CloudNativePG Cluster, kind Cluster is shared by multiple projects
+// hence manifests of other api groups should be skipped before, see IsCNPGCluster.
+func (dep *CNPGCluster) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	if !IsCNPGCluster(dep.APIVersion) {
+		return nil, &imgErrors.ImageError{
+			Message: fmt.Sprintf("kind '%s' of apiVersion '%s' is not a CloudNativePG cluster", KindCNPGCluster, dep.APIVersion),
+		}
+	}
+
+	images := &Image{
+		Kind:  KindCNPGCluster,
+		Name:  dep.Name,
+		Image: make([]string, 0),
+	}
+
+	images.addImageOrDefault(dep.Spec.ImageName, componentPostgreSQL)
+
+	return images, nil
+}
+
+// IsCNPGCluster reports whether kind Cluster of the apiVersion passed is the one of CloudNativePG.
+func IsCNPGCluster(apiVersion string) bool {
+	return strings.HasPrefix(apiVersion, cnpgGroup+"/")
+}
+
+// Get identifies images from Strimzi Kafka.
+func (dep *Kafka) Get(dataMap string, _ string, _ *logrus.Logger) (*Image, error) {
+	if err := yaml.Unmarshal([]byte(dataMap), &dep); err != nil {
+		return nil, err
+	}
+
+	images := &Image{
+		Kind:  KindKafka,
+		Name:  dep.Name,
+		Image: make([]string, 0),
+	}
+
+	components := []struct {
+		name      string
+		component *strimziComponent
+	}{
+		{name: componentKafka, component: dep.Spec.Kafka},
+		{name: componentZookeeper, component: dep.Spec.Zookeeper},
+		{name: componentKafkaExporter, component: dep.Spec.KafkaExporter},
+		{name: componentCruiseControl, component: dep.Spec.CruiseControl},
+	}
+
+	if dep.Spec.EntityOperator != nil {
+		components = append(components, []struct {
+			name      string
+			component *strimziComponent
+		}{
+			{name: componentTopicOperator, component: dep.Spec.EntityOperator.TopicOperator},
+			{name: componentUserOperator, component: dep.Spec.EntityOperator.UserOperator},
+			{name: componentTLSSidecar, component: dep.Spec.EntityOperator.TLSSidecar},
+		}...)
+	}
+
+	// Only the components that are enabled in the CR are deployed by the operator.
+	for _, component := range components {
+		if component.component == nil {
+			continue
+		}
+
+		images.addImageOrDefault(component.component.Image, component.name)
+	}
+
+	return images, nil
+}
+
+// NewElasticsearch returns new instance of Elasticsearch.
+func NewElasticsearch() ImagesInterface {
+	return &Elasticsearch{}
+}
+
+// NewKibana returns new instance of Kibana.
+func NewKibana() ImagesInterface {
+	return &Kibana{}
+}
+
+// NewCNPGCluster returns new instance of CNPGCluster.
+func NewCNPGCluster() ImagesInterface {
+	return &CNPGCluster{}
+}
+
+// NewKafka returns new instance of Kafka.
+func NewKafka() ImagesInterface {
+	return &Kafka{}
+}
@@ -0,0 +1,303 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+const notesFileSuffix = "NOTES.txt"
+
+var lookupObjectsExtensions = []string{".yaml", ".yml", ".json"}
+
+// lookupClientProvider serves the objects read from LookupObjects to the `lookup` template function, in place of the cluster.
+type lookupClientProvider struct {
+	objects []*unstructured.Unstructured
+}
+
+// lookupResource serves the objects of a kind, only Get and List are used by the `lookup` template function.
+// Objects without a namespace are returned regardless of the namespace looked up, so that the ones written by hand need not set one.
+type lookupResource struct {
+	dynamic.NamespaceableResourceInterface
+
+	groupResource schema.GroupResource
+	objects       []*unstructured.Unstructured
+	namespace     string
+}
+
+// renderWithLookup renders the chart the same way the dry-run install does for `helm template`,
+// except that the `lookup` template function returns the objects read from LookupObjects.
+// With Validate the chart is rendered for the capabilities of the cluster and the manifests are validated against it,
+// as with `helm template --validate`.
+func (image *Images) renderWithLookup(ctx context.Context, actionConfig *action.Configuration, client *action.Install,
+	chartRequested *chart.Chart, vals map[string]interface{},
+) (*release.Release, error) {
+	objects, err := readLookupObjects(image.LookupObjects)
+	if err != nil {
+		return nil, err
+	}
+
+	image.log.Debugf("rendering helm chart '%s' with %d object(s) for lookup from '%s'", image.chart, len(objects), strings.Join(image.LookupObjects, ", "))
+
+	if err = chartutil.ProcessDependenciesWithMerge(chartRequested, vals); err != nil {
+		return nil, err
+	}
+
+	caps, err := getRenderCapabilities(actionConfig, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if chartRequested.Metadata.KubeVersion != "" && !chartutil.IsCompatibleRange(chartRequested.Metadata.KubeVersion, caps.KubeVersion.String()) {
+		return nil, fmt.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", chartRequested.Metadata.KubeVersion, caps.KubeVersion.String())
+	}
+
+	options := chartutil.ReleaseOptions{Name: client.ReleaseName, Namespace: client.Namespace, Revision: 1, IsInstall: !client.IsUpgrade, IsUpgrade: client.IsUpgrade}
+
+	valuesToRender, err := chartutil.ToRenderValuesWithSchemaValidation(chartRequested, vals, options, caps, client.SkipSchemaValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	files, err := engine.RenderWithClientProvider(chartRequested, valuesToRender, &lookupClientProvider{objects: objects})
+	if err != nil {
+		return nil, err
+	}
+
+	for file := range files {
+		if strings.HasSuffix(file, notesFileSuffix) {
+			delete(files, file)
+		}
+	}
+
+	hooks, manifests, err := releaseutil.SortManifests(files, nil, releaseutil.InstallOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestDoc := bytes.NewBuffer(nil)
+
+	if client.IncludeCRDs {
+		for _, crd := range chartRequested.CRDObjects() {
+			_, _ = fmt.Fprintf(manifestDoc, "---\n# Source: %s\n%s\n", crd.Filename, string(crd.File.Data))
+		}
+	}
+
+	for _, manifest := range manifests {
+		_, _ = fmt.Fprintf(manifestDoc, "---\n# Source: %s\n%s\n", manifest.Name, manifest.Content)
+	}
+
+	if client.PostRenderer != nil {
+		if manifestDoc, err = client.PostRenderer.Run(manifestDoc); err != nil {
+			return nil, fmt.Errorf("error while running post render on files: %w", err)
+		}
+	}
+
+	if !client.ClientOnly {
+		if _, err = actionConfig.KubeClient.Build(bytes.NewBufferString(manifestDoc.String()), !client.DisableOpenAPIValidation); err != nil {
+			return nil, fmt.Errorf("unable to build kubernetes objects from release manifest: %w", err)
+		}
+	}
+
+	return &release.Release{Name: client.ReleaseName, Namespace: client.Namespace, Manifest: manifestDoc.String(), Hooks: hooks}, nil
+}
+
+// getRenderCapabilities returns the capabilities the chart is rendered for, the ones of the cluster unless the install is client only,
+// else the defaults along with the kube version and API versions set, same as the install does.
+func getRenderCapabilities(actionConfig *action.Configuration, client *action.Install) (*chartutil.Capabilities, error) {
+	if client.ClientOnly {
+		caps := chartutil.DefaultCapabilities.Copy()
+		if client.KubeVersion != nil {
+			caps.KubeVersion = *client.KubeVersion
+		}
+
+		caps.APIVersions = append(caps.APIVersions, client.APIVersions...)
+
+		return caps, nil
+	}
+
+	if err := actionConfig.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	discoveryClient, err := actionConfig.RESTClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not get Kubernetes discovery client: %w", err)
+	}
+
+	discoveryClient.Invalidate()
+
+	kubeVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("could not get server version from Kubernetes: %w", err)
+	}
+
+	apiVersions, err := action.GetVersionSet(discoveryClient)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("could not get apiVersions from Kubernetes: %w", err)
+	}
+
+	return &chartutil.Capabilities{
+		APIVersions: apiVersions,
+		KubeVersion: chartutil.KubeVersion{Version: kubeVersion.GitVersion, Major: kubeVersion.Major, Minor: kubeVersion.Minor},
+		HelmVersion: chartutil.DefaultCapabilities.HelmVersion,
+	}, nil
+}
+
+// GetClientFor returns the objects of the kind, to be looked up by the `lookup` template function.
+func (provider *lookupClientProvider) GetClientFor(apiVersion, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+	gvk := schema.FromAPIVersionAndKind(apiVersion, kind)
+
+	objects := make([]*unstructured.Unstructured, 0)
+
+	for _, object := range provider.objects {
+		if object.GroupVersionKind() == gvk {
+			objects = append(objects, object)
+		}
+	}
+
+	return &lookupResource{
+		groupResource: schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind)},
+		objects:       objects,
+	}, true, nil
+}
+
+func (resource *lookupResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &lookupResource{groupResource: resource.groupResource, objects: resource.objects, namespace: namespace}
+}
+
+func (resource *lookupResource) Get(_ context.Context, name string, _ metav1.GetOptions, _ ...string) (*unstructured.Unstructured, error) {
+	for _, object := range resource.inNamespace() {
+		if object.GetName() == name {
+			return object.DeepCopy(), nil
+		}
+	}
+
+	return nil, apierrors.NewNotFound(resource.groupResource, name)
+}
+
+func (resource *lookupResource) List(_ context.Context, _ metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}}
+
+	for _, object := range resource.inNamespace() {
+		list.Items = append(list.Items, *object.DeepCopy())
+	}
+
+	return list, nil
+}
+
+func (resource *lookupResource) inNamespace() []*unstructured.Unstructured {
+	if len(resource.namespace) == 0 {
+		return resource.objects
+	}
+
+	return slices.DeleteFunc(slices.Clone(resource.objects), func(object *unstructured.Unstructured) bool {
+		return len(object.GetNamespace()) != 0 && object.GetNamespace() != resource.namespace
+	})
+}
+
+// readLookupObjects reads the kubernetes objects from the files, or from the YAML and JSON files under the directories.
+// Lists, ex: the output of `kubectl get secrets -o yaml`, are read as the objects they hold.
+func readLookupObjects(paths []string) ([]*unstructured.Unstructured, error) {
+	objects := make([]*unstructured.Unstructured, 0)
+
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() || (file != path && !slices.Contains(lookupObjectsExtensions, strings.ToLower(filepath.Ext(file)))) {
+				return nil
+			}
+
+			fileObjects, err := readLookupObjectsFile(file)
+			if err != nil {
+				return err
+			}
+
+			objects = append(objects, fileObjects...)
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
+
+func readLookupObjectsFile(file string) ([]*unstructured.Unstructured, error) {
+	content, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	defer content.Close()
+
+	objects := make([]*unstructured.Unstructured, 0)
+	decoder := utilyaml.NewYAMLOrJSONDecoder(content, 4096) //nolint:mnd
+
+	for {
+		object := &unstructured.Unstructured{}
+		if err = decoder.Decode(&object.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+
+			return nil, &imgErrors.ImageError{Message: fmt.Sprintf("reading objects for lookup from '%s' errored with '%v'", file, err)}
+		}
+
+		if len(object.Object) == 0 {
+			continue
+		}
+
+		if len(object.GetAPIVersion()) == 0 || len(object.GetKind()) == 0 {
+			return nil, &imgErrors.ImageError{Message: fmt.Sprintf("objects for lookup from '%s' should set both apiVersion and kind", file)}
+		}
+
+		if !object.IsList() {
+			objects = append(objects, object)
+
+			continue
+		}
+
+		if err = object.EachListItem(func(item runtime.Object) error {
+			if listItem, ok := item.(*unstructured.Unstructured); ok {
+				objects = append(objects, listItem)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package pkg
+
+import (
+	"fmt"
+	"slices"
+
+	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"github.com/nikhilsbhat/helm-images/pkg/k8s"
+)
+
+const kindChart = "Chart"
+
+// getImagesFromManifests extracts the images from all the manifests rendered together.
+// Failure of extracting images from a manifest, be it an error or a panic, is isolated to that manifest and is returned as a warning,
+// unless Strict is set in which case the run fails on the first such manifest.
+// Manifests skipped for their kind are collected under skipped, for the coverage report.
+func (image *Images) getImagesFromManifests(kubeKindTemplates []string, namespace string) ([]*k8s.Image, []k8s.Warning, error) {
+	images := make([]*k8s.Image, 0)
+	warnings := make([]k8s.Warning, 0)
+	skips := image.GetResourcesToSkip()
+	image.skipped = nil
+
+	injection := image.getInjectionContext(kubeKindTemplates, namespace)
+
+	for _, kubeKindTemplate := range kubeKindTemplates {
+		imagesFound, warning := image.getImagesFromManifest(kubeKindTemplate, skips, injection)
+		if warning != nil {
+			if err := image.addWarning(&warnings, *warning); err != nil {
+				return nil, nil, err
+			}
+
+			continue
+		}
+
+		images = append(images, imagesFound...)
+	}
+
+	return image.resolveReferences(images), warnings, nil
+}
+
+// getImagesFromManifest extracts the images from a single manifest, recovering from the panics of the extractors.
+//
+//nolint:nonamedreturns
+func (image *Images) getImagesFromManifest(
+	kubeKindTemplate string, skips []Skip, injection injectionContext,
+) (images []*k8s.Image, warning *k8s.Warning) {
+	var currentManifestName, currentKind string
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			images = nil
+			warning = &k8s.Warning{
+				Kind:    currentKind,
+				Name:    currentManifestName,
+				Message: fmt.Sprintf("extracting images panicked with '%v'", recovered),
+			}
+		}
+	}()
+
+	currentManifestName, currentKind, err := image.getManifestMetadata(kubeKindTemplate)
+	if err != nil {
+		return nil, &k8s.Warning{
+			Kind:    currentKind,
+			Name:    currentManifestName,
+			Message: fmt.Sprintf("reading metadata of the manifest errored with '%v'", err),
+		}
+	}
+
+	if !slices.Contains(image.Kind, currentKind) {
+		image.log.Debugf("either helm-images plugin does not support kind '%s' "+
+			"at the moment or manifest might not have images to filter", currentKind)
+
+		image.addSkipped(currentKind, currentManifestName, kubeKindTemplate)
+
+		return nil, nil
+	}
+
+	if image.shouldSkipResource(skips, currentManifestName, currentKind) {
+		image.log.Debugf("Skipping '%s' bearing name '%s' since it is set to skip.", currentKind, currentManifestName)
+
+		return nil, nil
+	}
+
+	image.log.Debugf("fetching images from '%s' of kind '%s'", currentKind, currentManifestName)
+
+	imagesFound, err := image.getImage(currentKind, kubeKindTemplate, injection)
+	if err != nil {
+		return nil, &k8s.Warning{Kind: currentKind, Name: currentManifestName, Message: fmt.Sprintf("extracting images errored with '%v'", err)}
+	}
+
+	return imagesFound, nil
+}
+
+// addWarning records the warning, or returns it as an error when Strict is set.
+func (image *Images) addWarning(warnings *[]k8s.Warning, warning k8s.Warning) error {
+	if image.Strict {
+		return &imgErrors.ImageError{
+			Message: fmt.Sprintf("'%s' of kind '%s' failed: %s", warning.Name, warning.Kind, warning.Message),
+		}
+	}
+
+	image.log.Warnf("skipping '%s' of kind '%s' as it failed: %s", warning.Name, warning.Kind, warning.Message)
+
+	*warnings = append(*warnings, warning)
+
+	return nil
+}
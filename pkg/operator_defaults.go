@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ghodss/yaml"
+	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"github.com/nikhilsbhat/helm-images/pkg/k8s"
+)
+
+// OperatorDefaults holds the images operators default to, for the components of the resources those do not set one.
+// These override the built-in defaults of matching kind and component, see k8s.DefaultOperatorImages.
+type OperatorDefaults struct {
+	Defaults []k8s.OperatorDefault `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+}
+
+// SetOperatorDefaults loads the operator defaults from the file set under OperatorDefaults, over the built-in ones.
+func (image *Images) SetOperatorDefaults() error {
+	image.operatorDefaults = k8s.DefaultOperatorImages()
+
+	if len(image.OperatorDefaults) == 0 {
+		return nil
+	}
+
+	image.log.Debugf("loading operator defaults from '%s'", image.OperatorDefaults)
+
+	content, err := os.ReadFile(image.OperatorDefaults)
+	if err != nil {
+		return err
+	}
+
+	var operatorDefaults OperatorDefaults
+	if err = yaml.Unmarshal(content, &operatorDefaults); err != nil {
+		return &imgErrors.ImageError{Message: fmt.Sprintf("parsing operator defaults '%s' errored with '%v'", image.OperatorDefaults, err)}
+	}
+
+	for _, operatorDefault := range operatorDefaults.Defaults {
+		if len(operatorDefault.Kind) == 0 || len(operatorDefault.Component) == 0 || len(operatorDefault.BaseImage) == 0 {
+			return &imgErrors.ImageError{
+				Message: fmt.Sprintf("operator defaults '%s' should set kind, component and baseImage of every entry", image.OperatorDefaults),
+			}
+		}
+
+		image.setOperatorDefault(operatorDefault)
+	}
+
+	return nil
+}
+
+func (image *Images) setOperatorDefault(operatorDefault k8s.OperatorDefault) {
+	for index, existing := range image.operatorDefaults {
+		if existing.Kind == operatorDefault.Kind && existing.Component == operatorDefault.Component {
+			image.operatorDefaults[index] = operatorDefault
+
+			return
+		}
+	}
+
+	image.operatorDefaults = append(image.operatorDefaults, operatorDefault)
+}
+
+func (image *Images) getOperatorDefaults() []k8s.OperatorDefault {
+	if image.operatorDefaults == nil {
+		return k8s.DefaultOperatorImages()
+	}
+
+	return image.operatorDefaults
+}
@@ -8,6 +8,9 @@ import (
 
 func (image *Images) setOutput(images []*k8s.Image) any {
 	images = image.FilterImagesByRegistriesNew(images)
+	if images == nil {
+		images = make([]*k8s.Image, 0)
+	}
 
 	var output any
 
@@ -16,9 +19,9 @@ func (image *Images) setOutput(images []*k8s.Image) any {
 	if image.table {
 		outputTable := make([][]string, 0)
 
-		outputTable = append(outputTable, []string{"Name", "Kind", "Image"})
+		outputTable = append(outputTable, []string{"Name", "Kind", "Type", "Image"})
 		for _, img := range images {
-			outputTable = append(outputTable, []string{img.Name, img.Kind, strings.Join(img.Image, ", ")})
+			outputTable = append(outputTable, []string{img.Name, img.Kind, img.Type, strings.Join(img.Image, ", ")})
 		}
 
 		output = outputTable
@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+const postRendererSource = "post-renderer/manifests.yaml"
+
+// normalizePostRendered adds the '# Source:' headers back to the manifests those lost them to the post-renderer,
+// ex: kustomize drops the comments, so that the manifests can still be split by GetTemplates.
+func normalizePostRendered(manifests []byte) []byte {
+	splitManifests := releaseutil.SplitManifests(string(manifests))
+
+	manifestsKeys := make([]string, 0, len(splitManifests))
+	for key := range splitManifests {
+		manifestsKeys = append(manifestsKeys, key)
+	}
+
+	sort.Sort(releaseutil.BySplitManifestsOrder(manifestsKeys))
+
+	var normalized bytes.Buffer
+
+	for _, manifestKey := range manifestsKeys {
+		manifest := strings.TrimSpace(splitManifests[manifestKey])
+		if len(manifest) == 0 {
+			continue
+		}
+
+		if !strings.HasPrefix(manifest, "# Source:") {
+			manifest = fmt.Sprintf("# Source: %s\n%s", postRendererSource, manifest)
+		}
+
+		_, _ = fmt.Fprintf(&normalized, "---\n%s\n", manifest)
+	}
+
+	return normalized.Bytes()
+}
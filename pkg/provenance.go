@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"github.com/nikhilsbhat/helm-images/pkg/k8s"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+)
+
+// getKeyring returns the keyring set under Keyring, defaulting to the one helm verifies the charts against, i.e. $GNUPGHOME/pubring.gpg.
+func (image *Images) getKeyring() string {
+	if len(image.Keyring) != 0 {
+		return image.Keyring
+	}
+
+	if gnupgHome, ok := os.LookupEnv("GNUPGHOME"); ok {
+		return filepath.Join(gnupgHome, "pubring.gpg")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		image.log.Warnf("finding home directory for the default keyring errored with '%v'", err)
+	}
+
+	return filepath.Join(homeDir, ".gnupg", "pubring.gpg")
+}
+
+// verifyChart locates the chart, fetching it along with its provenance file when remote, and verifies it against the keyring.
+// The result of the verification is recorded under provenance, while the path to the verified chart archive is returned to render from.
+func (image *Images) verifyChart() (string, error) {
+	settings := cli.New()
+	keyring := image.getKeyring()
+
+	client := action.NewInstall(new(action.Configuration))
+	image.setChartPathOptions(&client.ChartPathOptions)
+	client.Verify = true
+	client.Keyring = keyring
+
+	registryClient, err := image.newRegistryClient(settings)
+	if err != nil {
+		return "", err
+	}
+
+	client.SetRegistryClient(registryClient)
+
+	image.log.Debugf("verifying provenance of the chart '%s' against keyring '%s'", image.chart, keyring)
+
+	chartPath, err := client.LocateChart(image.chart, settings)
+	if err == nil {
+		if image.provenance, err = getProvenance(image.chart, chartPath, keyring); err == nil {
+			image.log.Debugf("provenance of the chart '%s' is verified, signed by '%s'", image.chart, strings.Join(image.provenance.SignedBy, ", "))
+
+			return chartPath, nil
+		}
+	}
+
+	image.provenance = &k8s.Provenance{Chart: image.chart, Verified: false, Message: err.Error()}
+
+	return "", &imgErrors.ImageError{Message: fmt.Sprintf("verifying provenance of the chart '%s' errored with '%v'", image.chart, err)}
+}
+
+// getProvenance verifies the chart archive against the provenance file next to it and returns who signed it.
+func getProvenance(chart, chartPath, keyring string) (*k8s.Provenance, error) {
+	verification, err := downloader.VerifyChart(chartPath, keyring)
+	if err != nil {
+		return nil, err
+	}
+
+	provenance := &k8s.Provenance{Chart: chart, Verified: true, FileHash: verification.FileHash}
+
+	if verification.SignedBy != nil {
+		for identity := range verification.SignedBy.Identities {
+			provenance.SignedBy = append(provenance.SignedBy, identity)
+		}
+
+		sort.Strings(provenance.SignedBy)
+
+		if verification.SignedBy.PrimaryKey != nil {
+			provenance.Fingerprint = fmt.Sprintf("%X", verification.SignedBy.PrimaryKey.Fingerprint)
+		}
+	}
+
+	return provenance, nil
+}
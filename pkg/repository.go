@@ -0,0 +1,241 @@
+package pkg
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"github.com/nikhilsbhat/helm-images/version"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// develVersion is the version constraint matching the development versions too, set by `helm template --devel` when no version is set.
+const develVersion = ">0.0.0-0"
+
+// setChartPathOptions sets the options for locating the chart from the chart repositories, same as the ones of `helm template`.
+func (image *Images) setChartPathOptions(options *action.ChartPathOptions) {
+	options.RepoURL = image.Repo
+	options.Username = image.Username
+	options.Password = image.Password
+	options.CaFile = image.CaFile
+	options.CertFile = image.CertFile
+	options.KeyFile = image.KeyFile
+	options.InsecureSkipTLSverify = image.InsecureSkipTLSVerify
+	options.PassCredentialsAll = image.PassCredentials
+	options.PlainHTTP = image.PlainHTTP
+	options.Version = image.Version
+
+	if len(image.Version) == 0 && image.Devel {
+		options.Version = develVersion
+	}
+}
+
+// locateChart locates the chart the same way it is located for rendering, pulling it from the chart repositories or OCI registries when not local.
+func (image *Images) locateChart(chartPath string) (string, error) {
+	settings := cli.New()
+
+	client := action.NewInstall(new(action.Configuration))
+	image.setChartPathOptions(&client.ChartPathOptions)
+
+	registryClient, err := image.newRegistryClient(settings)
+	if err != nil {
+		return "", err
+	}
+
+	client.SetRegistryClient(registryClient)
+
+	return client.LocateChart(chartPath, settings)
+}
+
+// loadChart locates the chart, the same way it is located for rendering, and loads it.
+func (image *Images) loadChart(ctx context.Context) (*chart.Chart, error) {
+	chartPath := image.chart
+
+	if isGitChart(image.chart) {
+		checkoutPath, cleanup, err := image.checkoutGitChart(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		defer cleanup()
+
+		chartPath = checkoutPath
+	}
+
+	chartPath, err := image.locateChart(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.Load(chartPath)
+}
+
+// getRepositoryFlags returns the flags of `helm template` for locating the chart from the chart repositories.
+// The password is never one of those, see getChartFromHelmBin.
+func (image *Images) getRepositoryFlags() []string {
+	flags := make([]string, 0)
+
+	for _, option := range [][2]string{
+		{"--repo", image.Repo},
+		{"--username", image.Username},
+		{"--ca-file", image.CaFile},
+		{"--cert-file", image.CertFile},
+		{"--key-file", image.KeyFile},
+	} {
+		if len(option[1]) != 0 {
+			flags = append(flags, option[0], option[1])
+		}
+	}
+
+	if image.InsecureSkipTLSVerify {
+		flags = append(flags, "--insecure-skip-tls-verify")
+	}
+
+	if image.PassCredentials {
+		flags = append(flags, "--pass-credentials")
+	}
+
+	if image.PlainHTTP {
+		flags = append(flags, "--plain-http")
+	}
+
+	if len(image.RegistryConfig) != 0 {
+		flags = append(flags, "--registry-config", image.RegistryConfig)
+	}
+
+	return flags
+}
+
+// newRegistryClient returns the client for pulling the charts from OCI registries.
+// Credentials set by the flags take precedence over the ones in the registry config, as with `helm registry login`.
+func (image *Images) newRegistryClient(settings *cli.EnvSettings) (*registry.Client, error) {
+	registryConfig := settings.RegistryConfig
+	if len(image.RegistryConfig) != 0 {
+		registryConfig = image.RegistryConfig
+	}
+
+	options := []registry.ClientOption{
+		registry.ClientOptEnableCache(true),
+		registry.ClientOptWriter(os.Stderr),
+		registry.ClientOptCredentialsFile(registryConfig),
+	}
+
+	if image.PlainHTTP {
+		options = append(options, registry.ClientOptPlainHTTP())
+	}
+
+	httpClient := &http.Client{Transport: http.DefaultTransport}
+
+	if len(image.CertFile) != 0 && len(image.KeyFile) != 0 || len(image.CaFile) != 0 || image.InsecureSkipTLSVerify {
+		tlsConf, err := image.newClientTLS()
+		if err != nil {
+			return nil, fmt.Errorf("creating TLS config for registry client errored with: %w", err)
+		}
+
+		httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf, Proxy: http.ProxyFromEnvironment}}
+		options = append(options, registry.ClientOptHTTPClient(httpClient))
+	}
+
+	// Credentials of the client are not used for pulling the charts, hence those are set on the authorizer instead.
+	// They are sent only to the registry of the chart, the others (ex: of its dependencies) use the ones from the registry config.
+	if registryHost := image.getRegistryHost(); len(registryHost) != 0 && (len(image.Username) != 0 || len(image.Password) != 0) {
+		store, err := newCredentialsStore(registryConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		chartCredential := auth.StaticCredential(registryHost, auth.Credential{Username: image.Username, Password: image.Password})
+		configCredential := credentials.Credential(store)
+
+		authorizer := auth.Client{
+			Client: httpClient,
+			Cache:  auth.NewCache(),
+			Credential: func(ctx context.Context, hostport string) (auth.Credential, error) {
+				if credential, err := chartCredential(ctx, hostport); err != nil || credential != auth.EmptyCredential {
+					return credential, err
+				}
+
+				return configCredential(ctx, hostport)
+			},
+		}
+		authorizer.SetUserAgent("helm-images/" + strings.TrimPrefix(version.Version, "v"))
+
+		options = append(options, registry.ClientOptAuthorizer(authorizer))
+	}
+
+	registryClient, err := registry.NewClient(options...)
+	if err != nil {
+		return nil, fmt.Errorf("creating registry client errored with: %w", err)
+	}
+
+	return registryClient, nil
+}
+
+// newCredentialsStore returns the store of the credentials from the registry config falling back to the ones of docker, same as the one helm builds.
+func newCredentialsStore(registryConfig string) (credentials.Store, error) {
+	storeOptions := credentials.StoreOptions{AllowPlaintextPut: true, DetectDefaultNativeStore: true}
+
+	store, err := credentials.NewStore(registryConfig, storeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("loading registry config '%s' errored with: %w", registryConfig, err)
+	}
+
+	dockerStore, err := credentials.NewStoreFromDocker(storeOptions)
+	if err != nil {
+		return store, nil //nolint:nilerr
+	}
+
+	return credentials.NewStoreWithFallbacks(store, dockerStore), nil
+}
+
+// getRegistryHost returns the host of the OCI registry the chart is pulled from, empty when the chart is not an OCI one.
+func (image *Images) getRegistryHost() string {
+	if !registry.IsOCI(image.chart) {
+		return ""
+	}
+
+	host, _, _ := strings.Cut(strings.TrimPrefix(image.chart, fmt.Sprintf("%s://", registry.OCIScheme)), "/")
+
+	return host
+}
+
+// newClientTLS returns the TLS config for authenticating with the registries, same as the one helm builds.
+func (image *Images) newClientTLS() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: image.InsecureSkipTLSVerify} //nolint:gosec
+
+	if len(image.CertFile) != 0 && len(image.KeyFile) != 0 {
+		cert, err := tls.LoadX509KeyPair(image.CertFile, image.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading key pair from cert '%s' and key '%s' errored with: %w", image.CertFile, image.KeyFile, err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(image.CaFile) != 0 {
+		caBundle, err := os.ReadFile(image.CaFile)
+		if err != nil {
+			return nil, err
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caBundle) {
+			return nil, &imgErrors.ImageError{Message: fmt.Sprintf("failed to append certificates from CA file '%s'", image.CaFile)}
+		}
+
+		config.RootCAs = certPool
+	}
+
+	return config, nil
+}
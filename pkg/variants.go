@@ -0,0 +1,286 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	imgErrors "github.com/nikhilsbhat/helm-images/pkg/errors"
+	"github.com/nikhilsbhat/helm-images/pkg/k8s"
+)
+
+const (
+	// VariantOutputUnion lists the union of images across the variants, along with the variants using each.
+	VariantOutputUnion = "union"
+	// VariantOutputVariant lists the images of each variant separately.
+	VariantOutputVariant = "variant"
+)
+
+// variant holds a named set of values files, ex: dev=values-dev.yaml,values-dev-overrides.yaml.
+type variant struct {
+	name       string
+	valueFiles ValueFiles
+}
+
+// imagesFromVariant holds the images identified from the chart rendered with the values of a variant.
+type imagesFromVariant struct {
+	name         string
+	images       []*k8s.Image
+	warnings     []k8s.Warning
+	skipped      []k8s.Skipped
+	placeholders []string
+	provenance   *k8s.Provenance
+}
+
+// getVariants returns the variants from the ones set under Variants, in the order they were set.
+func (image *Images) getVariants() ([]variant, error) {
+	variants := make([]variant, 0, len(image.Variants))
+
+	for _, rawVariant := range image.Variants {
+		name, valueFiles, found := strings.Cut(rawVariant, "=")
+		if !found || len(strings.TrimSpace(name)) == 0 || len(strings.TrimSpace(valueFiles)) == 0 {
+			return nil, &imgErrors.ImageError{
+				Message: fmt.Sprintf("variant '%s' should be of the form name=values.yaml[,values-override.yaml]", rawVariant),
+			}
+		}
+
+		name = strings.TrimSpace(name)
+		if slices.ContainsFunc(variants, func(v variant) bool { return v.name == name }) {
+			return nil, &imgErrors.ImageError{Message: fmt.Sprintf("variant '%s' is set more than once", name)}
+		}
+
+		files := ValueFiles(strings.Split(valueFiles, ","))
+		if err := files.Valid(); err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, variant{name: name, valueFiles: files})
+	}
+
+	return variants, nil
+}
+
+// GetImagesFromVariants renders the chart once for each of the variants, on top of the values set by --values and --set,
+// and lists either the union of images across them or the images of each based on VariantOutput.
+func (image *Images) GetImagesFromVariants(ctx context.Context) error {
+	variants, err := image.getVariants()
+	if err != nil {
+		return err
+	}
+
+	imagesFromVariants, err := image.collectImagesFromVariants(ctx, variants)
+	if err != nil {
+		return err
+	}
+
+	switch image.VariantOutput {
+	case VariantOutputUnion, "":
+		return image.renderVariantsUnion(imagesFromVariants)
+	case VariantOutputVariant:
+		return image.renderVariants(imagesFromVariants)
+	default:
+		return &imgErrors.ImageError{
+			Message: fmt.Sprintf("variant output '%s' is not supported, it should be one of %s|%s", image.VariantOutput, VariantOutputUnion, VariantOutputVariant),
+		}
+	}
+}
+
+func (image *Images) collectImagesFromVariants(ctx context.Context, variants []variant) ([]imagesFromVariant, error) {
+	originalValueFiles := image.ValueFiles
+
+	defer func() {
+		image.ValueFiles = originalValueFiles
+	}()
+
+	imagesFromVariants := make([]imagesFromVariant, 0, len(variants))
+
+	for _, currentVariant := range variants {
+		image.log.Debugf("fetching the images from chart '%s' with the values of variant '%s'", image.chart, currentVariant.name)
+
+		image.ValueFiles = append(slices.Clone(originalValueFiles), currentVariant.valueFiles...)
+		image.skipped = nil
+		image.placeholders = nil
+		image.provenance = nil
+
+		chart, err := image.getChartManifests(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("rendering chart with the values of variant '%s' errored with: %w", currentVariant.name, err)
+		}
+
+		images, warnings, err := image.getImagesFromManifests(image.GetTemplates(chart), image.namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		if image.Exhaustive {
+			if images, warnings, err = image.getOptionalImages(ctx, images, warnings); err != nil {
+				return nil, err
+			}
+		}
+
+		imagesFromVariants = append(imagesFromVariants, imagesFromVariant{
+			name:         currentVariant.name,
+			images:       images,
+			warnings:     warnings,
+			skipped:      image.skipped,
+			placeholders: image.placeholders,
+			provenance:   image.provenance,
+		})
+	}
+
+	return imagesFromVariants, nil
+}
+
+// renderVariantsUnion renders the images used by any of the variants, each listed once along with the variants using it.
+// With json and yaml the union is rendered along with the warnings (named by the variant they are from), coverage and placeholders of all the variants.
+// The provenance is rendered only when it is the same for all the variants, else it is warned about to be listed per variant.
+func (image *Images) renderVariantsUnion(imagesFromVariants []imagesFromVariant) error {
+	union := make([]k8s.VariantImage, 0)
+	skipped := make([]k8s.Skipped, 0)
+	warnings := make([]k8s.Warning, 0)
+	placeholders := make([]string, 0)
+	indexes := make(map[string]int)
+
+	for _, fromVariant := range imagesFromVariants {
+		skipped = append(skipped, fromVariant.skipped...)
+
+		for _, warning := range fromVariant.warnings {
+			warning.Message = fmt.Sprintf("variant '%s': %s", fromVariant.name, warning.Message)
+			warnings = append(warnings, warning)
+		}
+
+		for _, placeholder := range fromVariant.placeholders {
+			if !slices.Contains(placeholders, placeholder) {
+				placeholders = append(placeholders, placeholder)
+			}
+		}
+
+		for _, imageName := range GetImagesFromKind(image.FilterImagesByRegistriesNew(fromVariant.images)) {
+			index, found := indexes[imageName]
+			if !found {
+				indexes[imageName] = len(union)
+				union = append(union, k8s.VariantImage{Image: imageName, Variants: []string{fromVariant.name}})
+
+				continue
+			}
+
+			if !slices.Contains(union[index].Variants, fromVariant.name) {
+				union[index].Variants = append(union[index].Variants, fromVariant.name)
+			}
+		}
+	}
+
+	if image.json || image.yaml {
+		provenance, agreed := getVariantsProvenance(imagesFromVariants)
+		if !agreed {
+			message := fmt.Sprintf("provenance of the chart differs across the variants, list it per variant with --variant-output %s", VariantOutputVariant)
+			image.log.Warn(message)
+
+			warnings = append(warnings, k8s.Warning{Message: message})
+		}
+
+		return image.renderer.Render(k8s.Images{
+			ImagesFromRelease: union,
+			Warnings:          warnings,
+			Coverage:          skipped,
+			Provenance:        provenance,
+			Placeholders:      placeholders,
+		})
+	}
+
+	var output any = union
+
+	switch {
+	case image.table:
+		outputTable := [][]string{{"Image", "Variants"}}
+		for _, variantImage := range union {
+			outputTable = append(outputTable, []string{variantImage.Image, strings.Join(variantImage.Variants, ", ")})
+		}
+
+		output = outputTable
+	case image.isSimpleOutput():
+		imageNames := make([]string, 0, len(union))
+		for _, variantImage := range union {
+			imageNames = append(imageNames, variantImage.Image)
+		}
+
+		output = strings.Join(imageNames, "\n")
+	}
+
+	if err := image.renderer.Render(output); err != nil {
+		return err
+	}
+
+	return image.renderCoverage(skipped)
+}
+
+// getVariantsProvenance returns the provenance of the chart when it is the same for all the variants, the chart could differ across
+// the renders of the variants, ex: the latest version of it in the chart repository changing in between.
+func getVariantsProvenance(imagesFromVariants []imagesFromVariant) (*k8s.Provenance, bool) {
+	if len(imagesFromVariants) == 0 {
+		return nil, true
+	}
+
+	provenance := imagesFromVariants[0].provenance
+
+	for _, fromVariant := range imagesFromVariants[1:] {
+		if !reflect.DeepEqual(fromVariant.provenance, provenance) {
+			return nil, false
+		}
+	}
+
+	return provenance, true
+}
+
+// renderVariants renders the images of each variant separately, the same way images from multiple charts are with --charts-dir.
+func (image *Images) renderVariants(imagesFromVariants []imagesFromVariant) error {
+	if image.isSimpleOutput() || image.table {
+		skipped := make([]k8s.Skipped, 0)
+		sections := make([]string, 0, len(imagesFromVariants))
+		outputTable := [][]string{{"Variant", "Name", "Kind", "Type", "Image"}}
+
+		for _, fromVariant := range imagesFromVariants {
+			skipped = append(skipped, fromVariant.skipped...)
+			images := image.FilterImagesByRegistriesNew(fromVariant.images)
+
+			imageNames := GetImagesFromKind(images)
+			if image.UniqueImages {
+				imageNames = GetUniqEntries(imageNames)
+			}
+
+			sections = append(sections, fmt.Sprintf("# %s\n%s", fromVariant.name, strings.Join(imageNames, "\n")))
+
+			for _, img := range images {
+				outputTable = append(outputTable, []string{fromVariant.name, img.Name, img.Kind, img.Type, strings.Join(img.Image, ", ")})
+			}
+		}
+
+		var output any = strings.Join(sections, "\n\n")
+		if image.table {
+			output = outputTable
+		}
+
+		if err := image.renderer.Render(output); err != nil {
+			return err
+		}
+
+		return image.renderCoverage(skipped)
+	}
+
+	imagesFromAllVariants := make([]k8s.Images, 0, len(imagesFromVariants))
+
+	for _, fromVariant := range imagesFromVariants {
+		imagesFromAllVariants = append(imagesFromAllVariants, k8s.Images{
+			ImagesFromRelease: image.setOutput(fromVariant.images),
+			NameSpace:         fromVariant.name,
+			Warnings:          fromVariant.warnings,
+			Coverage:          fromVariant.skipped,
+			Provenance:        fromVariant.provenance,
+			Placeholders:      fromVariant.placeholders,
+		})
+	}
+
+	return image.renderer.Render(imagesFromAllVariants)
+}